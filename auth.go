@@ -0,0 +1,233 @@
+package goose4
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Authorizer decides whether a request may be served, such as by checking its credentials
+// or where it came from. Authorizers are set per endpoint with Goose4's Authorizers, so
+// that probes may stay public while endpoints which reveal more are locked down
+type Authorizer interface {
+	Authorized(r *http.Request) bool
+}
+
+// AuthorizerFunc allows an ordinary function to be used as an Authorizer
+type AuthorizerFunc func(r *http.Request) bool
+
+// Authorized calls f(r)
+func (f AuthorizerFunc) Authorized(r *http.Request) bool {
+	return f(r)
+}
+
+// Challenger is implemented by Authorizers which check credentials. Requests they refuse are
+// served 401 with a WWW-Authenticate header of each challenge, such as `Basic realm="goose4"`,
+// so that browsers prompt for credentials. Requests refused by other Authorizers are served 403
+type Challenger interface {
+	Challenges() []string
+}
+
+// challenges returns the challenges of a, if any
+func challenges(a Authorizer) []string {
+	if c, ok := a.(Challenger); ok {
+		return c.Challenges()
+	}
+
+	return nil
+}
+
+type basicAuth struct {
+	username, password string
+}
+
+// BasicAuth returns an Authorizer allowing requests which carry username and password
+// with HTTP basic authentication
+func BasicAuth(username, password string) Authorizer {
+	return basicAuth{username, password}
+}
+
+func (a basicAuth) Authorized(r *http.Request) bool {
+	u, p, ok := r.BasicAuth()
+
+	// Both are compared regardless, so as not to reveal which was wrong by timing
+	return ok && equal(u, a.username)&equal(p, a.password) == 1
+}
+
+func (a basicAuth) Challenges() []string {
+	return []string{`Basic realm="goose4", charset="UTF-8"`}
+}
+
+type bearerAuth []string
+
+// BearerAuth returns an Authorizer allowing requests which carry any of tokens as a
+// bearer token in their Authorization header
+func BearerAuth(tokens ...string) Authorizer {
+	return bearerAuth(tokens)
+}
+
+func (a bearerAuth) Authorized(r *http.Request) bool {
+	token, ok := bearer(r)
+	if !ok {
+		return false
+	}
+
+	for _, t := range a {
+		if equal(token, t) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a bearerAuth) Challenges() []string {
+	return []string{`Bearer realm="goose4"`}
+}
+
+type cidrAuth []*net.IPNet
+
+// CIDRAuth returns an Authorizer allowing requests from any of cidrs, which may each be either
+// a CIDR block, such as "10.0.0.0/8", or a single IP address. Requests are matched by their
+// RemoteAddr, and so by the address of any proxy in front of the service
+func CIDRAuth(cidrs ...string) (Authorizer, error) {
+	nets := make(cidrAuth, len(cidrs))
+
+	for i, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			ip := net.ParseIP(c)
+			if ip == nil {
+				return nil, fmt.Errorf("goose4: invalid IP address %q", c)
+			}
+
+			nets[i] = &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, fmt.Errorf("goose4: %w", err)
+		}
+
+		nets[i] = n
+	}
+
+	return nets, nil
+}
+
+func (a cidrAuth) Authorized(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range a {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type anyAuth []Authorizer
+
+// AnyAuth returns an Authorizer allowing requests allowed by any of authorizers, such
+// as those either from an internal network or carrying a token
+func AnyAuth(authorizers ...Authorizer) Authorizer {
+	return anyAuth(authorizers)
+}
+
+func (a anyAuth) Authorized(r *http.Request) bool {
+	for _, a0 := range a {
+		if a0.Authorized(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Challenges returns those of every one of a, as any may allow a request
+func (a anyAuth) Challenges() []string {
+	var c []string
+	for _, a0 := range a {
+		c = append(c, challenges(a0)...)
+	}
+
+	return c
+}
+
+type allAuth []Authorizer
+
+// AllAuth returns an Authorizer allowing requests allowed by every one of authorizers
+func AllAuth(authorizers ...Authorizer) Authorizer {
+	return allAuth(authorizers)
+}
+
+func (a allAuth) Authorized(r *http.Request) bool {
+	for _, a0 := range a {
+		if !a0.Authorized(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Challenges returns those of every one of a, as each must allow a request
+func (a allAuth) Challenges() []string {
+	return anyAuth(a).Challenges()
+}
+
+// authorized returns whether r may be served endpoint e, and where it may not,
+// the challenges of the Authorizer which refused it
+func (g Goose4) authorized(e Endpoint, r *http.Request) (bool, []string) {
+	a, ok := g.Authorizers[e]
+	if !ok || a == nil || a.Authorized(r) {
+		return true, nil
+	}
+
+	return false, challenges(a)
+}
+
+// refuse returns the response to a request refused by an Authorizer with challenges: 401
+// with those challenges where there are any, so that clients may offer credentials, or 403
+func refuse(w http.ResponseWriter, challenges []string) (int, []byte, error) {
+	if len(challenges) == 0 {
+		body, err := Error{http.StatusForbidden, "Forbidden"}.Marshal()
+
+		return http.StatusForbidden, body, err
+	}
+
+	for _, c := range challenges {
+		w.Header().Add("WWW-Authenticate", c)
+	}
+
+	body, err := Error{http.StatusUnauthorized, "Unauthorized"}.Marshal()
+
+	return http.StatusUnauthorized, body, err
+}
+
+// bearer returns the bearer token carried by r, if any
+func bearer(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return token, true
+}
+
+// equal compares a and b in constant time, returning 1 where they are equal
+func equal(a, b string) int {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b))
+}
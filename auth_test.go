@@ -0,0 +1,127 @@
+package goose4
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAuthorizers(t *testing.T) {
+	cidrs, err := CIDRAuth("10.0.0.0/8", "192.168.1.1", "::1")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	basic := func(u, p string) func(r *http.Request) {
+		return func(r *http.Request) { r.SetBasicAuth(u, p) }
+	}
+	header := func(v string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("Authorization", v) }
+	}
+	remote := func(addr string) func(r *http.Request) {
+		return func(r *http.Request) { r.RemoteAddr = addr }
+	}
+
+	for _, test := range []struct {
+		title      string
+		authorizer Authorizer
+		request    func(r *http.Request)
+		expect     bool
+	}{
+		{"Basic auth, correct credentials", BasicAuth("ops", "s3cret"), basic("ops", "s3cret"), true},
+		{"Basic auth, wrong password", BasicAuth("ops", "s3cret"), basic("ops", "guess"), false},
+		{"Basic auth, wrong username", BasicAuth("ops", "s3cret"), basic("dev", "s3cret"), false},
+		{"Basic auth, no credentials", BasicAuth("ops", "s3cret"), func(*http.Request) {}, false},
+		{"Bearer auth, first token", BearerAuth("one", "two"), header("Bearer one"), true},
+		{"Bearer auth, second token", BearerAuth("one", "two"), header("bearer two"), true},
+		{"Bearer auth, wrong token", BearerAuth("one", "two"), header("Bearer three"), false},
+		{"Bearer auth, wrong scheme", BearerAuth("one", "two"), header("Basic one"), false},
+		{"Bearer auth, bare token", BearerAuth("one", "two"), header("one"), false},
+		{"Bearer auth, no tokens", BearerAuth(), header("Bearer "), false},
+		{"CIDR auth, within block", cidrs, remote("10.1.2.3:5678"), true},
+		{"CIDR auth, single address", cidrs, remote("192.168.1.1:5678"), true},
+		{"CIDR auth, IPv6 address", cidrs, remote("[::1]:5678"), true},
+		{"CIDR auth, outside block", cidrs, remote("192.168.1.2:5678"), false},
+		{"CIDR auth, no port", cidrs, remote("10.1.2.3"), true},
+		{"CIDR auth, garbage", cidrs, remote("nonsense"), false},
+		{"Any auth, one allowed", AnyAuth(cidrs, BearerAuth("one")), header("Bearer one"), true},
+		{"Any auth, none allowed", AnyAuth(cidrs, BearerAuth("one")), header("Bearer two"), false},
+		{"All auth, all allowed", AllAuth(cidrs, BearerAuth("one")), func(r *http.Request) { r.RemoteAddr = "10.0.0.1:1"; r.Header.Set("Authorization", "Bearer one") }, true},
+		{"All auth, one refused", AllAuth(cidrs, BearerAuth("one")), header("Bearer one"), false},
+		{"Func auth, allowed", AuthorizerFunc(func(r *http.Request) bool { return r.Header.Get("X-Internal") == "yes" }), func(r *http.Request) { r.Header.Set("X-Internal", "yes") }, true},
+		{"Func auth, refused", AuthorizerFunc(func(r *http.Request) bool { return r.Header.Get("X-Internal") == "yes" }), func(*http.Request) {}, false},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/service/config", nil)
+			test.request(r)
+
+			if received := test.authorizer.Authorized(r); received != test.expect {
+				t.Errorf("expected %v, received %v", test.expect, received)
+			}
+		})
+	}
+}
+
+func TestCIDRAuthInvalid(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0/33", "not-an-address", "10.0.0"} {
+		t.Run(cidr, func(t *testing.T) {
+			if _, err := CIDRAuth(cidr); err == nil {
+				t.Errorf("expected error, received none")
+			}
+		})
+	}
+}
+
+func TestServeAuthorized(t *testing.T) {
+	internal, _ := CIDRAuth("10.0.0.0/8")
+
+	g, _ := NewGoose4(Config{})
+	g.Authorizers = map[Endpoint]Authorizer{
+		EndpointConfig:      BearerAuth("s3cret"),
+		EndpointStatus:      BasicAuth("ops", "s3cret"),
+		EndpointMetrics:     internal,
+		EndpointHealthcheck: AnyAuth(internal, BasicAuth("ops", "s3cret"), BearerAuth("s3cret")),
+	}
+
+	for _, test := range []struct {
+		path             string
+		auth             string
+		expectStatusCode int
+		expectBody       string
+		expectChallenges []string
+	}{
+		{"/service/config", "", 401, `{"status":401,"message":"Unauthorized"}`, []string{`Bearer realm="goose4"`}},
+		{"/service/config", "Bearer guess", 401, `{"status":401,"message":"Unauthorized"}`, []string{`Bearer realm="goose4"`}},
+		{"/service/status", "", 401, `{"status":401,"message":"Unauthorized"}`, []string{`Basic realm="goose4", charset="UTF-8"`}},
+		{"/service/metrics", "", 403, `{"status":403,"message":"Forbidden"}`, nil},
+		{"/service/healthcheck", "", 401, `{"status":401,"message":"Unauthorized"}`, []string{`Basic realm="goose4", charset="UTF-8"`, `Bearer realm="goose4"`}},
+		{"/service/config", "Bearer s3cret", 200, "", nil},
+		{"/service/status", "Basic b3BzOnMzY3JldA==", 200, "", nil},
+		{"/service/healthcheck", "Bearer s3cret", 200, "", nil},
+		{"/service/healthcheck/gtg", "", 200, `"OK"`, nil},
+		{"/service/livez", "", 200, "ok", nil},
+	} {
+		t.Run(test.path+" "+test.auth, func(t *testing.T) {
+			r := httptest.NewRequest("GET", test.path, nil)
+			if test.auth != "" {
+				r.Header.Set("Authorization", test.auth)
+			}
+
+			w := newrw()
+			g.ServeHTTP(w, r)
+
+			if w.status != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.status)
+			}
+
+			if test.expectBody != "" && w.body != test.expectBody {
+				t.Errorf("expected %q, received %q", test.expectBody, w.body)
+			}
+
+			if c := w.headers.Values("WWW-Authenticate"); !reflect.DeepEqual(c, test.expectChallenges) {
+				t.Errorf("expected %q, received %q", test.expectChallenges, c)
+			}
+		})
+	}
+}
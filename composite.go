@@ -0,0 +1,82 @@
+package goose4
+
+import (
+	"context"
+	"fmt"
+)
+
+// AllOf returns a Test which passes only where every one of children passes. Where all
+// children pass but some only with warnings, the Test warns
+func AllOf(name string, children ...Test) Test {
+	return composite(name, len(children), children)
+}
+
+// AnyOf returns a Test which passes where at least one of children passes, such as for
+// a dependency served from more than one region
+func AnyOf(name string, children ...Test) Test {
+	return composite(name, 1, children)
+}
+
+// Quorum returns a Test which passes where at least n of children pass, such as for
+// a replicated datastore which needs a majority of nodes to be available. Where n is
+// not between 1 and the number of children the Test always fails, reporting as much
+func Quorum(name string, n int, children ...Test) Test {
+	if n < 1 || n > len(children) {
+		return Test{
+			Name: name,
+			Probe: func(context.Context) Outcome {
+				return Outcome{Err: fmt.Errorf("quorum of %d is not between 1 and %d", n, len(children))}
+			},
+		}
+	}
+
+	return composite(name, n, children)
+}
+
+// composite returns a Test which runs children in parallel, passing where at least required
+// of them pass. Each child's result is served alongside that of the composite Test
+func composite(name string, required int, children []Test) Test {
+	return Test{
+		Name: name,
+		Probe: func(ctx context.Context) Outcome {
+			// Children run within the deadline of the composite test, rather
+			// than with a default timeout of their own
+			h := NewHealthcheck(children)
+			h.runTests(ctx, testAll)
+
+			var passed, warned int
+			for _, c := range h.Tests {
+				// Severity only decides how a child's failure affects health; a
+				// warning or informational child which failed is still of no use
+				// to a quorum. Only children warning of trouble ahead have passed
+				switch {
+				case c.Result == ResultPassed:
+					passed++
+				case c.Result == ResultWarning && c.warned:
+					passed++
+					warned++
+				}
+			}
+
+			o := Outcome{
+				Message: fmt.Sprintf("%d of %d passed, %d required", passed, len(children), required),
+				Details: map[string]interface{}{
+					"passed":   passed,
+					"total":    len(children),
+					"required": required,
+				},
+				children: h.Tests,
+			}
+
+			switch {
+			case passed < required:
+				o.Err = fmt.Errorf("too few tests passed")
+			case passed-warned < required:
+				o.Err = fmt.Errorf("too few tests passed without warnings")
+				o.Warning = true
+			}
+
+			return o
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package goose4
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComposite(t *testing.T) {
+	pass := Test{Name: "pass", F: HealthTestSuccess}
+	fail := Test{Name: "fail", F: HealthTestFailure}
+	warn := Test{Name: "warn", F: HealthTestFailure, Severity: SeverityWarning}
+	soon := Test{Name: "soon", Probe: func(context.Context) Outcome { return Outcome{Err: errors.New("expiring"), Warning: true} }}
+	info := Test{Name: "info", F: HealthTestFailure, Severity: SeverityInfo}
+	hang := Test{Name: "hang", Check: HealthCheckHang, Timeout: 10 * time.Millisecond}
+
+	for _, test := range []struct {
+		title          string
+		test           Test
+		expectedResult string
+		expectedMsg    string
+	}{
+		{"All of, all passing", AllOf("all", pass, pass), ResultPassed, "2 of 2 passed, 2 required"},
+		{"All of, an informational child failing", AllOf("all", pass, pass, info), ResultFailed, "2 of 3 passed, 3 required"},
+		{"Any of, only an informational child failing", AnyOf("any", info), ResultFailed, "0 of 1 passed, 1 required"},
+		{"All of, one failing", AllOf("all", pass, fail, pass), ResultFailed, "2 of 3 passed, 3 required"},
+		{"All of, one warning", AllOf("all", pass, soon), ResultWarning, "2 of 2 passed, 2 required"},
+		{"All of, a warning child failing", AllOf("all", pass, warn), ResultFailed, "1 of 2 passed, 2 required"},
+		{"Any of, only a warning child failing", AnyOf("any", warn), ResultFailed, "0 of 1 passed, 1 required"},
+		{"Any of, one passing", AnyOf("any", fail, pass, fail), ResultPassed, "1 of 3 passed, 1 required"},
+		{"Any of, none passing", AnyOf("any", fail, fail), ResultFailed, "0 of 2 passed, 1 required"},
+		{"Any of, one warning and one passing", AnyOf("any", soon, pass), ResultPassed, "2 of 2 passed, 1 required"},
+		{"Any of, only a warning", AnyOf("any", soon), ResultWarning, "1 of 1 passed, 1 required"},
+		{"Quorum reached", Quorum("quorum", 2, pass, fail, pass), ResultPassed, "2 of 3 passed, 2 required"},
+		{"Quorum not reached", Quorum("quorum", 2, pass, fail, fail), ResultFailed, "1 of 3 passed, 2 required"},
+		{"Quorum of zero", Quorum("quorum", 0, pass, fail), ResultFailed, "quorum of 0 is not between 1 and 2"},
+		{"Quorum larger than its children", Quorum("quorum", 3, pass, pass), ResultFailed, "quorum of 3 is not between 1 and 2"},
+		{"Quorum with a hung child", Quorum("quorum", 2, pass, hang, pass), ResultPassed, "2 of 3 passed, 2 required"},
+		{"Nested composites", AllOf("nested", AnyOf("any", fail, pass), pass), ResultPassed, "2 of 2 passed, 2 required"},
+		{"No children", AllOf("empty"), ResultPassed, "0 of 0 passed, 0 required"},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := test.test
+			t0.Timeout = 50 * time.Millisecond
+			t0.run(context.Background(), 0)
+
+			if t0.Result != test.expectedResult {
+				t.Errorf("expected %q, received %q", test.expectedResult, t0.Result)
+			}
+
+			if t0.Message != test.expectedMsg {
+				t.Errorf("expected %q, received %q", test.expectedMsg, t0.Message)
+			}
+		})
+	}
+}
+
+func TestCompositeChildren(t *testing.T) {
+	t0 := AnyOf("cassandra",
+		Test{Name: "node-1", F: HealthTestFailure},
+		Test{Name: "node-2", F: HealthTestSuccess},
+		Quorum("region", 1, Test{Name: "node-3", F: HealthTestSuccess}),
+	)
+	t0.run(context.Background(), 0)
+
+	if len(t0.Children) != 3 {
+		t.Fatalf("expected 3 children, received %d", len(t0.Children))
+	}
+
+	b, err := json.Marshal(Healthcheck{Tests: []Test{t0}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, expect := range []string{
+		`"children":[{"test_name":"node-1","test_result":"failed"`,
+		`{"test_name":"node-2","test_result":"passed"`,
+		`"children":[{"test_name":"node-3","test_result":"passed"`,
+	} {
+		if !strings.Contains(string(b), expect) {
+			t.Errorf("expected %s to contain %s", b, expect)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package goose4
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCORSHeaders are the request headers allowed by a CORS policy without AllowedHeaders
+var defaultCORSHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+
+// CORS is a policy for cross-origin requests, as per https://fetch.spec.whatwg.org/#http-cors-protocol,
+// allowing endpoints to be called from browsers on other origins, such as a dashboard. The zero
+// value allows no cross-origin requests
+type CORS struct {
+	// AllowedOrigins are origins which may make requests, such as "https://dashboard.example.com".
+	// "*" allows any origin, unless AllowCredentials is set, when it allows none: otherwise any
+	// website could read endpoints with the credentials browsers hold for a service
+	AllowedOrigins []string
+
+	// AllowOrigin, where set, is called for origins not in AllowedOrigins, and
+	// allows them where it returns true
+	AllowOrigin func(origin string) bool
+
+	// AllowCredentials allows requests to carry cookies and Authorization headers
+	AllowCredentials bool
+
+	// AllowedHeaders are the request headers allowed, defaulting to Origin, Content-Type,
+	// Accept and Authorization
+	AllowedHeaders []string
+
+	// ExposedHeaders are response headers, beyond the CORS-safelisted ones, which
+	// scripts may read
+	ExposedHeaders []string
+
+	// MaxAge is how long browsers may cache the result of a preflight request. Zero
+	// leaves this to browsers
+	MaxAge time.Duration
+}
+
+// allows returns whether requests from origin are allowed, and whether they
+// are allowed only by way of a wildcard
+func (c CORS) allows(origin string) (ok, wildcard bool) {
+	for _, o := range c.AllowedOrigins {
+		switch o {
+		case origin:
+			return true, false
+		case "*":
+			wildcard = !c.AllowCredentials
+		}
+	}
+
+	if wildcard {
+		return true, true
+	}
+
+	return c.AllowOrigin != nil && c.AllowOrigin(origin), false
+}
+
+// apply sets the CORS headers of a response from endpoint e to r, returning
+// whether r is a preflight request, which should be served no further
+func (c CORS) apply(e Endpoint, w http.ResponseWriter, r *http.Request) (preflight bool) {
+	origin := r.Header.Get("Origin")
+	preflight = r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+	ok, wildcard := c.allows(origin)
+
+	// Wildcard responses are the same for every origin, and so may be served regardless.
+	// Otherwise responses vary by origin, and caches must be told as much
+	switch {
+	case wildcard:
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	case origin == "":
+		return false
+	default:
+		w.Header().Add("Vary", "Origin")
+		if !ok {
+			return preflight
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if len(c.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		}
+
+		return false
+	}
+
+	headers := c.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methodsFor(e), ", "))
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+
+	if c.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+	}
+
+	return true
+}
@@ -0,0 +1,89 @@
+package goose4
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	dashboard := CORS{
+		AllowedOrigins:   []string{"https://dashboard.example.com"},
+		AllowOrigin:      func(o string) bool { return strings.HasSuffix(o, ".internal.example.com") },
+		AllowCredentials: true,
+		ExposedHeaders:   []string{"X-Request-Id"},
+		MaxAge:           10 * time.Minute,
+	}
+
+	for _, test := range []struct {
+		title            string
+		cors             CORS
+		method           string
+		path             string
+		headers          map[string]string
+		expectStatusCode int
+		expectHeaders    map[string]string
+	}{
+		{"Wildcard, no origin", CORS{AllowedOrigins: []string{"*"}}, "GET", "/service/config", nil, 200,
+			map[string]string{"Access-Control-Allow-Origin": "*", "Vary": "Accept"}},
+		{"Wildcard, with origin", CORS{AllowedOrigins: []string{"*"}}, "GET", "/service/config", map[string]string{"Origin": "https://example.com"}, 200,
+			map[string]string{"Access-Control-Allow-Origin": "*", "Access-Control-Allow-Credentials": ""}},
+		{"Wildcard with credentials", CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}, "GET", "/service/config", map[string]string{"Origin": "https://evil.example.com"}, 200,
+			map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Credentials": "", "Vary": "Origin"}},
+		{"Wildcard with credentials, listed origin", CORS{AllowedOrigins: []string{"*", "https://dashboard.example.com"}, AllowCredentials: true}, "GET", "/service/config", map[string]string{"Origin": "https://dashboard.example.com"}, 200,
+			map[string]string{"Access-Control-Allow-Origin": "https://dashboard.example.com", "Access-Control-Allow-Credentials": "true", "Vary": "Origin"}},
+		{"Wildcard with credentials, preflight", CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}, "OPTIONS", "/service/config", map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": "GET"}, 204,
+			map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""}},
+		{"Disabled", CORS{}, "GET", "/service/config", map[string]string{"Origin": "https://example.com"}, 200,
+			map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"}},
+		{"Listed origin", dashboard, "GET", "/service/status", map[string]string{"Origin": "https://dashboard.example.com"}, 200,
+			map[string]string{"Access-Control-Allow-Origin": "https://dashboard.example.com", "Access-Control-Allow-Credentials": "true", "Access-Control-Expose-Headers": "X-Request-Id", "Vary": "Origin"}},
+		{"Matched origin", dashboard, "GET", "/service/status", map[string]string{"Origin": "https://ops.internal.example.com"}, 200,
+			map[string]string{"Access-Control-Allow-Origin": "https://ops.internal.example.com"}},
+		{"Unknown origin", dashboard, "GET", "/service/status", map[string]string{"Origin": "https://evil.example.com"}, 200,
+			map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Credentials": "", "Vary": "Origin"}},
+		{"No origin", dashboard, "GET", "/service/status", nil, 200,
+			map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Accept"}},
+		{"Preflight", dashboard, "OPTIONS", "/service/status", map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "GET"}, 204,
+			map[string]string{"Access-Control-Allow-Origin": "https://dashboard.example.com", "Access-Control-Allow-Methods": "GET", "Access-Control-Allow-Headers": "Origin, Content-Type, Accept, Authorization", "Access-Control-Max-Age": "600", "Content-Type": ""}},
+		{"Preflight for maintenance", dashboard, "OPTIONS", "/service/maintenance", map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "PUT"}, 204,
+			map[string]string{"Access-Control-Allow-Methods": "GET, PUT, DELETE"}},
+		{"Preflight from an unknown origin", dashboard, "OPTIONS", "/service/status", map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": "GET"}, 204,
+			map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""}},
+		{"Preflight for an unknown route", dashboard, "OPTIONS", "/service/floopydoop", map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "GET"}, 404,
+			nil},
+		{"Preflight with custom headers", CORS{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"X-Custom"}}, "OPTIONS", "/service/status", map[string]string{"Origin": "https://example.com", "Access-Control-Request-Method": "GET"}, 204,
+			map[string]string{"Access-Control-Allow-Origin": "*", "Access-Control-Allow-Headers": "X-Custom", "Access-Control-Max-Age": ""}},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+			g.CORS = test.cors
+
+			// Preflights are answered before authorization, as browsers send them without credentials
+			g.Authorizers = map[Endpoint]Authorizer{EndpointStatus: BearerAuth("s3cret")}
+
+			r := httptest.NewRequest(test.method, test.path, nil)
+			for k, v := range test.headers {
+				r.Header.Set(k, v)
+			}
+
+			if test.method == "GET" {
+				r.Header.Set("Authorization", "Bearer s3cret")
+			}
+
+			w := newrw()
+			g.ServeHTTP(w, r)
+
+			if w.status != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.status)
+			}
+
+			for k, v := range test.expectHeaders {
+				if received := w.headers.Get(k); received != v {
+					t.Errorf("%s: expected %q, received %q", k, v, received)
+				}
+			}
+		})
+	}
+}
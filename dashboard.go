@@ -0,0 +1,98 @@
+package goose4
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// DefaultDashboardRefresh is how often the dashboard of a Goose4 returned from
+// NewGoose4 refreshes itself
+const DefaultDashboardRefresh = 10 * time.Second
+
+// dashboard is the data the dashboard is rendered from
+type dashboard struct {
+	Title   string
+	Refresh int
+	Now     time.Time
+
+	// Config and System are decoded from their JSON, so that they read just as they do
+	// elsewhere. They, and Healthcheck, are nil where withheld from a request
+	Config      interface{}
+	System      interface{}
+	Healthcheck *Healthcheck
+
+	// Maintenance and Shutdown are nil where not in effect, or withheld along with System
+	Maintenance *Maintenance
+	Shutdown    *Shutdown
+}
+
+// serveDashboard serves a single, self-contained, page of the config, system details and
+// latest healthcheck results of a service, for engineers to see everything at once. Each
+// is withheld unless the request is authorized for the endpoint which would serve it
+func serveDashboard(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	w.Header().Set("Content-Type", contentTypes[formatHTML])
+
+	d := dashboard{
+		Title:   g.title(EndpointDashboard),
+		Refresh: int(g.DashboardRefresh / time.Second),
+		Now:     time.Now(),
+	}
+
+	var err error
+
+	if ok, _ := g.authorized(EndpointConfig, r); ok {
+		if d.Config, err = reencode(g.config); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if ok, _ := g.authorized(EndpointStatus, r); ok {
+		s := NewSystem(g.boot)
+		s.Legacy = g.LegacyFormat
+
+		if d.System, err = reencode(s); err != nil {
+			return 0, nil, err
+		}
+
+		// Maintenance and shutdown are served by status, so are withheld alongside it
+		d.Maintenance = g.maintenance()
+		d.Shutdown = g.shutdown()
+	}
+
+	if ok, _ := g.authorized(EndpointHealthcheck, r); ok {
+		h, _ := g.serveTestsWithin(testAll, g.dashboardInterval())
+		d.Healthcheck = &h
+	}
+
+	buf := new(bytes.Buffer)
+	err = pages.ExecuteTemplate(buf, "dashboard.html", d)
+
+	return 0, buf.Bytes(), err
+}
+
+// dashboardInterval is the least time the dashboard reuses results for: that
+// between refreshes, or MinInterval where longer
+func (g Goose4) dashboardInterval() time.Duration {
+	d := g.DashboardRefresh
+	if d == 0 {
+		d = DefaultDashboardRefresh
+	}
+
+	if g.MinInterval > d {
+		d = g.MinInterval
+	}
+
+	return d
+}
+
+// reencode marshals v into JSON, and decodes it again with the order of its keys kept
+func reencode(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(json.NewDecoder(bytes.NewReader(b)))
+}
@@ -0,0 +1,158 @@
+package goose4
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServeDashboard(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		setup       func(g *Goose4)
+		auth        string
+		expect      []string
+		expectNever []string
+	}{
+		{"Everything", func(g *Goose4) {},
+			"",
+			[]string{
+				`<meta http-equiv="refresh" content="10">`,
+				"<title>orders dashboard</title>",
+				`<span class="banner fail">failed</span>`,
+				"<td>db</td>\n<td class=\"pass\">passed</td>",
+				"<td>eu</td>\n<td class=\"fail\">failed</td>",
+				"<tr><th>machine_name</th>",
+				"<tr><th>artifact_id</th><td>orders</td></tr>",
+			},
+			[]string{"Withheld", "banner warn", "Shutting down", "<script src", "<link"},
+		},
+		{"Without refreshing", func(g *Goose4) { g.DashboardRefresh = 0 },
+			"",
+			[]string{"<title>orders dashboard</title>"},
+			[]string{"http-equiv"},
+		},
+		{"In maintenance", func(g *Goose4) { g.StartMaintenance(Maintenance{GTG: true, Reason: "deploying <v2>"}) },
+			"",
+			[]string{`<div class="banner warn">In maintenance since `, ": deploying &lt;v2&gt; (GTG)</div>"},
+			nil,
+		},
+		{"Shutting down", func(g *Goose4) { g.shut.current = &Shutdown{Since: time.Now(), DrainPeriod: time.Minute} },
+			"",
+			[]string{`<div class="banner fail">Shutting down since `, ", draining for 1m0s</div>"},
+			nil,
+		},
+		{"Withheld banners", func(g *Goose4) {
+			g.Authorizers = map[Endpoint]Authorizer{EndpointStatus: BearerAuth("s3cret")}
+			g.StartMaintenance(Maintenance{Reason: "deploying"})
+			g.shut.current = &Shutdown{Since: time.Now()}
+		},
+			"",
+			[]string{"<td>db</td>"},
+			[]string{"banner warn", "Shutting down", "deploying"},
+		},
+		{"Authorized banners", func(g *Goose4) {
+			g.Authorizers = map[Endpoint]Authorizer{EndpointStatus: BearerAuth("s3cret")}
+			g.StartMaintenance(Maintenance{Reason: "deploying"})
+			g.shut.current = &Shutdown{Since: time.Now()}
+		},
+			"Bearer s3cret",
+			[]string{"banner warn", "Shutting down", "deploying"},
+			nil,
+		},
+		{"Withheld sections", func(g *Goose4) {
+			g.Authorizers = map[Endpoint]Authorizer{EndpointConfig: BearerAuth("s3cret"), EndpointStatus: BearerAuth("s3cret")}
+		},
+			"",
+			[]string{"<h2>System</h2>\n<p class=\"muted\">Withheld</p>", "<h2>Config</h2>\n<p class=\"muted\">Withheld</p>", "<td>db</td>"},
+			[]string{"machine_name", "artifact_id"},
+		},
+		{"Authorized sections", func(g *Goose4) {
+			g.Authorizers = map[Endpoint]Authorizer{EndpointConfig: BearerAuth("s3cret"), EndpointStatus: BearerAuth("s3cret")}
+		},
+			"Bearer s3cret",
+			[]string{"machine_name", "artifact_id"},
+			[]string{"Withheld"},
+		},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			g, _ := NewGoose4(Config{ArtifactID: "orders"})
+			g.AddTest(Test{Name: "db", F: HealthTestSuccess})
+			g.AddTest(AnyOf("region", Test{Name: "eu", F: HealthTestFailure}))
+			test.setup(&g)
+
+			r := httptest.NewRequest("GET", "/service/", nil)
+			if test.auth != "" {
+				r.Header.Set("Authorization", test.auth)
+			}
+
+			w := newrw()
+			g.ServeHTTP(w, r)
+
+			if w.status != 200 {
+				t.Errorf("expected 200, received %d", w.status)
+			}
+
+			if ct := w.headers.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+				t.Errorf("expected %q, received %q", "text/html; charset=utf-8", ct)
+			}
+
+			for _, s := range test.expect {
+				if !strings.Contains(w.body, s) {
+					t.Errorf("expected %s to contain %q", w.body, s)
+				}
+			}
+
+			for _, s := range test.expectNever {
+				if strings.Contains(w.body, s) {
+					t.Errorf("expected %s not to contain %q", w.body, s)
+				}
+			}
+		})
+	}
+}
+
+func TestDashboardReusesResults(t *testing.T) {
+	for _, test := range []struct {
+		title      string
+		setup      func(g *Goose4)
+		expectRuns int32
+	}{
+		{"Refreshing", func(g *Goose4) {}, 1},
+		{"Without refreshing", func(g *Goose4) { g.DashboardRefresh = 0 }, 1},
+		{"Refreshing faster than tests may run", func(g *Goose4) { g.DashboardRefresh = time.Nanosecond; g.MinInterval = time.Minute }, 1},
+		{"Refreshing constantly", func(g *Goose4) { g.DashboardRefresh = time.Nanosecond }, 3},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			var runs int32
+
+			g, _ := NewGoose4(Config{})
+			g.AddTest(Test{Name: "db", F: func() bool { atomic.AddInt32(&runs, 1); return true }})
+			test.setup(&g)
+
+			for i := 0; i < 3; i++ {
+				if i > 0 {
+					time.Sleep(time.Millisecond)
+				}
+
+				g.ServeHTTP(newrw(), httptest.NewRequest("GET", "/service/", nil))
+			}
+
+			if r := atomic.LoadInt32(&runs); r != test.expectRuns {
+				t.Errorf("expected %d runs, received %d", test.expectRuns, r)
+			}
+		})
+	}
+}
+
+func TestDashboardEndpoint(t *testing.T) {
+	for _, path := range []string{"/service", "/service/", "/"} {
+		g, _ := NewGoose4(Config{})
+
+		if e := g.endpoint(path); e != EndpointDashboard {
+			t.Errorf("%s: expected %q, received %q", path, EndpointDashboard, e)
+		}
+	}
+}
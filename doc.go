@@ -29,5 +29,56 @@ Mounting se4 is just as easy:
     http.Handle("/service/", se4)
     panic(http.ListenAndServe(":80", nil))
 
+Browsing to /service/ itself shows a dashboard of config, system details and the latest
+healthcheck results, refreshing every DashboardRefresh.
+
+Endpoints may be served from elsewhere by setting BasePath, or by mounting each
+endpoint individually:
+
+    se4.BasePath = "/internal/se4"
+    http.Handle("/internal/se4/", se4)
+
+    mux.Handle("GET /gtg", se4.Handler(goose4.EndpointGTG))
+
+The config, status and healthcheck endpoints serve JSON by default, but honour the Accept
+header and the format query parameter to serve aligned plain text, YAML or HTML:
+
+    curl localhost/service/healthcheck?format=text
+
+Endpoints which reveal details of a service, such as its git SHA, build machine and
+hostname, may be locked down, leaving probes public. Metrics carry build details too:
+
+    internal, _ := goose4.CIDRAuth("10.0.0.0/8")
+    private := goose4.AnyAuth(internal, goose4.BearerAuth(token))
+    se4.Authorizers = map[goose4.Endpoint]goose4.Authorizer{
+        goose4.EndpointConfig:  private,
+        goose4.EndpointStatus:  private,
+        goose4.EndpointMetrics: private,
+    }
+
+By default any origin may call endpoints from a browser, without credentials. This may
+be restricted, such as to an internal dashboard:
+
+    se4.CORS = goose4.CORS{
+        AllowedOrigins:   []string{"https://dashboard.example.com"},
+        AllowCredentials: true,
+        MaxAge:           10 * time.Minute,
+    }
+
+Instances may be taken out of their load balancer without being stopped, such as
+during deploys, by starting maintenance:
+
+    se4.StartMaintenance(goose4.Maintenance{GTG: true, Reason: "deploying", Until: time.Now().Add(10 * time.Minute)})
+
+Or, where MaintenanceToken is set, over HTTP:
+
+    curl -X PUT -H "Authorization: Bearer $TOKEN" -d '{"gtg":true,"reason":"deploying","duration":"10m"}' localhost/service/maintenance
+    curl -X DELETE -H "Authorization: Bearer $TOKEN" localhost/service/maintenance
+
+On shutdown, GTG and readyz may be failed for DrainPeriod before an http.Server stops
+accepting requests, giving load balancers time to notice:
+
+    se4.Shutdown(ctx, srv)
+
 */
 package goose4
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -30,20 +31,37 @@ func main() {
 	}
 
 	// Create and add a healthcheck test pointing to a real function
-	se4.AddTest(goose4.Test{
+	err = se4.AddTest(goose4.Test{
 		Name:           "Check something works or something",
 		RequiredForASG: true,
 		RequiredForGTG: true,
 		F:              dummyCheck, // Note: no brackets
 	})
+	if err != nil {
+		panic(err)
+	}
 
 	// Add a truly anonymous function
-	se4.AddTest(goose4.Test{
+	err = se4.AddTest(goose4.Test{
 		Name:           "Some important thing",
 		RequiredForASG: true,
 		RequiredForGTG: true,
 		F:              func() bool { return true },
 	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Add a context aware test which gives up after two seconds
+	err = se4.AddTest(goose4.Test{
+		Name:           "Some slow dependency",
+		RequiredForGTG: true,
+		Timeout:        2 * time.Second,
+		Check:          slowCheck,
+	})
+	if err != nil {
+		panic(err)
+	}
 
 	// Mount Goose4 handler for all se4 routes
 	http.Handle("/service/", se4)
@@ -54,3 +72,12 @@ func dummyCheck() bool {
 	time.Sleep(1 * time.Second)
 	return true
 }
+
+func slowCheck(ctx context.Context) error {
+	select {
+	case <-time.After(3 * time.Second):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
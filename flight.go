@@ -0,0 +1,82 @@
+package goose4
+
+import (
+	"sync"
+	"time"
+)
+
+// flight coalesces runs of tests: concurrent requests for the same mode share a
+// single in-flight run, and where a minimum interval is set recent results are
+// reused rather than running tests again
+type flight struct {
+	sync.Mutex
+
+	calls  map[int]*call
+	recent map[int]*call
+
+	// gen is incremented by forget, so that runs begun beforehand aren't reused
+	gen int
+}
+
+// call is a single run of tests, which may be shared by many requests
+type call struct {
+	wg sync.WaitGroup
+
+	h    Healthcheck
+	errs bool
+}
+
+// do returns the results of run for mode, joining an in-flight run where there is one,
+// or reusing results which finished less than minInterval ago
+func (f *flight) do(mode int, minInterval time.Duration, run func() (Healthcheck, bool)) (Healthcheck, bool) {
+	f.Lock()
+
+	if f.calls == nil {
+		f.calls = make(map[int]*call)
+		f.recent = make(map[int]*call)
+	}
+
+	if c, ok := f.recent[mode]; ok && minInterval > 0 && time.Since(c.h.ReportTime) < minInterval {
+		f.Unlock()
+
+		return c.h, c.errs
+	}
+
+	if c, ok := f.calls[mode]; ok {
+		f.Unlock()
+		c.wg.Wait()
+
+		return c.h, c.errs
+	}
+
+	c := new(call)
+	c.wg.Add(1)
+	f.calls[mode] = c
+	gen := f.gen
+	f.Unlock()
+
+	c.h, c.errs = run()
+	c.wg.Done()
+
+	f.Lock()
+	if f.calls[mode] == c {
+		delete(f.calls, mode)
+	}
+	if f.gen == gen {
+		f.recent[mode] = c
+	}
+	f.Unlock()
+
+	return c.h, c.errs
+}
+
+// forget discards reusable results, and stops new requests joining runs already
+// in flight, such as when the tests being run have changed
+func (f *flight) forget() {
+	f.Lock()
+	defer f.Unlock()
+
+	f.gen++
+	f.calls = nil
+	f.recent = nil
+}
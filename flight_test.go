@@ -0,0 +1,83 @@
+package goose4
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightDo(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		callers     int
+		sequential  bool
+		minInterval time.Duration
+		expectRuns  int32
+	}{
+		{"Concurrent callers share a run", 10, false, 0, 1},
+		{"Sequential callers each run", 3, true, 0, 3},
+		{"Sequential callers within the minimum interval share a run", 3, true, time.Hour, 1},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			var runs int32
+			release := make(chan struct{})
+
+			run := func() (Healthcheck, bool) {
+				atomic.AddInt32(&runs, 1)
+				<-release
+
+				return Healthcheck{ReportTime: time.Now()}, true
+			}
+
+			f := new(flight)
+
+			if test.sequential {
+				close(release)
+				for i := 0; i < test.callers; i++ {
+					f.do(testAll, test.minInterval, run)
+				}
+			} else {
+				var wg sync.WaitGroup
+				for i := 0; i < test.callers; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if _, errs := f.do(testAll, test.minInterval, run); !errs {
+							t.Errorf("expected shared result")
+						}
+					}()
+				}
+
+				// Give every caller a chance to join the in-flight run
+				for atomic.LoadInt32(&runs) == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				time.Sleep(50 * time.Millisecond)
+				close(release)
+				wg.Wait()
+			}
+
+			if r := atomic.LoadInt32(&runs); r != test.expectRuns {
+				t.Errorf("expected %d runs, received %d", test.expectRuns, r)
+			}
+		})
+	}
+}
+
+func TestFlightDoModes(t *testing.T) {
+	var runs int32
+	run := func() (Healthcheck, bool) {
+		atomic.AddInt32(&runs, 1)
+		return Healthcheck{ReportTime: time.Now()}, false
+	}
+
+	f := new(flight)
+	f.do(testAll, time.Hour, run)
+	f.do(testGTGOnly, time.Hour, run)
+	f.do(testGTGOnly, time.Hour, run)
+
+	if r := atomic.LoadInt32(&runs); r != 2 {
+		t.Errorf("expected 2 runs, received %d", r)
+	}
+}
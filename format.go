@@ -0,0 +1,203 @@
+package goose4
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The SE4 spec expects durations in milliseconds and timestamps in ISO-8601. Older
+// versions of goose4 served go's own formats instead; the types in this file allow
+// either to be served, based on a Legacy flag
+
+// millis returns d in whole milliseconds, or as a go duration string where legacy is set
+func millis(d time.Duration, legacy bool) interface{} {
+	if legacy {
+		return d.String()
+	}
+
+	return int64(d / time.Millisecond)
+}
+
+// testJSON is how a Test is served
+type testJSON struct {
+	Name      string                 `json:"test_name"`
+	Severity  Severity               `json:"severity,omitempty"`
+	Result    string                 `json:"test_result"`
+	Duration  interface{}            `json:"duration_millis"`
+	TestTime  time.Time              `json:"tested_at"`
+	Message   string                 `json:"test_message,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Age       interface{}            `json:"result_age,omitempty"`
+	AgeMillis interface{}            `json:"result_age_millis,omitempty"`
+	Panic     string                 `json:"panic,omitempty"`
+	Children  []testJSON             `json:"children,omitempty"`
+}
+
+func (t Test) view(legacy bool) testJSON {
+	v := testJSON{
+		Name:     t.Name,
+		Severity: t.Severity,
+		Result:   t.Result,
+		Duration: millis(t.Duration, legacy),
+		TestTime: t.TestTime,
+		Message:  t.Message,
+		Details:  t.Details,
+		Panic:    t.Panic,
+	}
+
+	for _, c := range t.Children {
+		v.Children = append(v.Children, c.view(legacy))
+	}
+
+	if t.Age > 0 {
+		if legacy {
+			v.Age = millis(t.Age, legacy)
+		} else {
+			v.AgeMillis = millis(t.Age, legacy)
+		}
+	}
+
+	return v
+}
+
+// MarshalJSON serves a Test as described by the SE4 spec
+func (t Test) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.view(false))
+}
+
+// healthcheckJSON is how a Healthcheck is served
+type healthcheckJSON struct {
+	ReportTime time.Time   `json:"report_as_of"`
+	Duration   interface{} `json:"report_duration"`
+	Status     string      `json:"status"`
+	Tests      []testJSON  `json:"tests"`
+
+	Maintenance *maintenanceJSON `json:"maintenance,omitempty"`
+}
+
+// MarshalJSON serves a Healthcheck as described by the SE4 spec or, where Legacy
+// is set, as older versions of goose4 did
+func (h Healthcheck) MarshalJSON() ([]byte, error) {
+	v := healthcheckJSON{
+		ReportTime: h.ReportTime,
+		Duration:   millis(h.Duration, h.Legacy),
+		Status:     h.Status,
+		Tests:      make([]testJSON, len(h.Tests)),
+	}
+
+	for i, t := range h.Tests {
+		v.Tests[i] = t.view(h.Legacy)
+	}
+
+	if h.Maintenance != nil {
+		m := h.Maintenance.view()
+		v.Maintenance = &m
+	}
+
+	return json.Marshal(v)
+}
+
+// systemJSON is how a System is served
+type systemJSON struct {
+	MachineName string      `json:"machine_name"`
+	OSArch      string      `json:"os_arch"`
+	OSLoad      string      `json:"os_avgload"`
+	OSName      string      `json:"os_name"`
+	OSProcs     string      `json:"os_numprocessors"`
+	OSVersion   string      `json:"os_version"`
+	UpDuration  interface{} `json:"up_duration"`
+	UpSince     string      `json:"up_since"`
+}
+
+func (s System) view() systemJSON {
+	v := systemJSON{
+		MachineName: s.MachineName,
+		OSArch:      s.OSArch,
+		OSLoad:      s.OSLoad,
+		OSName:      s.OSName,
+		OSProcs:     s.OSProcs,
+		OSVersion:   s.OSVersion,
+		UpDuration:  millis(s.UpDuration, s.Legacy),
+		UpSince:     s.UpSince.Format(time.RFC3339Nano),
+	}
+
+	if s.Legacy {
+		v.UpSince = s.UpSince.String()
+	}
+
+	return v
+}
+
+// MarshalJSON serves a System as described by the SE4 spec or, where Legacy
+// is set, as older versions of goose4 did
+func (s System) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.view())
+}
+
+// maintenanceJSON is how Maintenance is served
+type maintenanceJSON struct {
+	Active bool   `json:"active"`
+	GTG    bool   `json:"gtg,omitempty"`
+	ASG    bool   `json:"asg,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Since  string `json:"since,omitempty"`
+	Until  string `json:"until,omitempty"`
+}
+
+// view returns how m is served, where a nil m is served as inactive
+func (m *Maintenance) view() maintenanceJSON {
+	if m == nil {
+		return maintenanceJSON{}
+	}
+
+	v := maintenanceJSON{
+		Active: true,
+		GTG:    m.GTG,
+		ASG:    m.ASG,
+		Reason: m.Reason,
+		Since:  m.Since.Format(time.RFC3339Nano),
+	}
+
+	if !m.Until.IsZero() {
+		v.Until = m.Until.Format(time.RFC3339Nano)
+	}
+
+	return v
+}
+
+// shutdownJSON is how Shutdown is served
+type shutdownJSON struct {
+	Since       string      `json:"since"`
+	DrainPeriod interface{} `json:"drain_period_millis"`
+}
+
+func (s Shutdown) view(legacy bool) shutdownJSON {
+	return shutdownJSON{
+		Since:       s.Since.Format(time.RFC3339Nano),
+		DrainPeriod: millis(s.DrainPeriod, legacy),
+	}
+}
+
+// MarshalJSON serves a Status as a single document of Config and System values.
+// Without it, System's MarshalJSON would be promoted and serve System alone
+func (s Status) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Config
+		systemJSON
+
+		Maintenance *maintenanceJSON `json:"maintenance,omitempty"`
+		Shutdown    *shutdownJSON    `json:"shutdown,omitempty"`
+	}{Config: s.Config, systemJSON: s.System.view()}
+
+	if s.Maintenance != nil {
+		m := s.Maintenance.view()
+		v.Maintenance = &m
+	}
+
+	if s.Shutdown != nil {
+		sd := s.Shutdown.view(s.System.Legacy)
+		v.Shutdown = &sd
+	}
+
+	return json.Marshal(v)
+}
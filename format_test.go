@@ -0,0 +1,81 @@
+package goose4
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	formatTime = time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	formatTest = Test{
+		Name:     "a_test",
+		Result:   ResultPassed,
+		Duration: 1500 * time.Millisecond,
+		TestTime: formatTime,
+	}
+)
+
+func TestHealthcheckMarshalJSON(t *testing.T) {
+	aged := formatTest
+	aged.Age = 2 * time.Second
+
+	for _, test := range []struct {
+		title  string
+		h      Healthcheck
+		expect string
+	}{
+		{"Spec format", Healthcheck{ReportTime: formatTime, Duration: 1502 * time.Millisecond, Status: HealthOK, Tests: []Test{formatTest}},
+			`{"report_as_of":"2017-04-01T12:30:00Z","report_duration":1502,"status":"ok","tests":[{"test_name":"a_test","test_result":"passed","duration_millis":1500,"tested_at":"2017-04-01T12:30:00Z"}]}`},
+		{"Legacy format", Healthcheck{ReportTime: formatTime, Duration: 1502 * time.Millisecond, Status: HealthOK, Tests: []Test{formatTest}, Legacy: true},
+			`{"report_as_of":"2017-04-01T12:30:00Z","report_duration":"1.502s","status":"ok","tests":[{"test_name":"a_test","test_result":"passed","duration_millis":"1.5s","tested_at":"2017-04-01T12:30:00Z"}]}`},
+		{"Spec format with age", Healthcheck{ReportTime: formatTime, Status: HealthOK, Tests: []Test{aged}},
+			`{"report_as_of":"2017-04-01T12:30:00Z","report_duration":0,"status":"ok","tests":[{"test_name":"a_test","test_result":"passed","duration_millis":1500,"tested_at":"2017-04-01T12:30:00Z","result_age_millis":2000}]}`},
+		{"Legacy format with age", Healthcheck{ReportTime: formatTime, Status: HealthOK, Tests: []Test{aged}, Legacy: true},
+			`{"report_as_of":"2017-04-01T12:30:00Z","report_duration":"0s","status":"ok","tests":[{"test_name":"a_test","test_result":"passed","duration_millis":"1.5s","tested_at":"2017-04-01T12:30:00Z","result_age":"2s"}]}`},
+		{"No tests", Healthcheck{ReportTime: formatTime, Status: HealthOK},
+			`{"report_as_of":"2017-04-01T12:30:00Z","report_duration":0,"status":"ok","tests":[]}`},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			b, err := json.Marshal(test.h)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if string(b) != test.expect {
+				t.Errorf("expected %s, received %s", test.expect, b)
+			}
+		})
+	}
+}
+
+func TestStatusMarshalJSON(t *testing.T) {
+	s := System{UpSince: formatTime, UpDuration: 90 * time.Second}
+
+	for _, test := range []struct {
+		title    string
+		legacy   bool
+		expected []string
+	}{
+		{"Spec format", false, []string{`"up_duration":90000`, `"up_since":"2017-04-01T12:30:00Z"`}},
+		{"Legacy format", true, []string{`"up_duration":"1m30s"`, `"up_since":"2017-04-01 12:30:00 +0000 UTC"`}},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			s.Legacy = test.legacy
+
+			b, err := json.Marshal(Status{Config: TestConfig, System: s})
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			// Config must survive System having its own MarshalJSON
+			expected := append(test.expected, strings.TrimSuffix(TestJSON, "}"))
+			for _, e := range expected {
+				if !strings.Contains(string(b), e) {
+					t.Errorf("expected %s to contain %s", b, e)
+				}
+			}
+		})
+	}
+}
@@ -1,91 +1,233 @@
 package goose4
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// Goose4 holds goose4 configuration and provides functions thereon
+// DefaultTestTimeout is the timeout given to tests which don't set their own by
+// a Goose4 returned from NewGoose4
+const DefaultTestTimeout = 10 * time.Second
+
+// Goose4 holds goose4 configuration and provides functions thereon.
+//
+// A Goose4 returned from NewGoose4 shares its tests, background runs, maintenance and
+// shutdown with every copy of it, such as one already mounted as a handler. A zero value
+// Goose4 does not: what it sets up on first use is seen only by it, and by copies made
+// afterwards, so copies mounted beforehand silently miss any change
 type Goose4 struct {
 	config Config
 	boot   time.Time
 
-	tests []Test
+	tests   *registry
+	sched   *scheduler
+	flight  *flight
+	started *latch
+	metrics *metrics
+	maint   *maintenance
+	shut    *shutdown
+
+	// BasePath is the path se4 endpoints are served under, such as DefaultBasePath.
+	// Requests which don't start with BasePath are matched as though it had already
+	// been stripped from them
+	BasePath string
+
+	// DefaultTimeout is the longest any Test without its own Timeout may run
+	// before being reported as timed out. Zero disables the timeout entirely
+	DefaultTimeout time.Duration
+
+	// MaxConcurrency limits how many tests are run at once, which may help services
+	// with a great many tests. Zero runs every test at once
+	MaxConcurrency int
+
+	// StatusCodes are the HTTP status codes served by the healthcheck endpoint
+	// for each aggregate health
+	StatusCodes StatusCodes
+
+	// LegacyFormat serves durations as go duration strings, and up_since in go's
+	// default time format, as goose4 did before following the SE4 spec
+	LegacyFormat bool
+
+	// MinInterval is how long results are reused for before tests are run again.
+	// Regardless of this, concurrent requests always share a single run of tests
+	MinInterval time.Duration
+
+	// CORS is the policy for cross-origin requests from browsers. NewGoose4 allows
+	// requests from any origin, without credentials
+	CORS CORS
+
+	// Authorizers restrict who may be served each endpoint. Endpoints without an
+	// Authorizer are served to anyone. Requests refused by one are served 401 where
+	// it is a Challenger, and 403 otherwise.
+	//
+	// EndpointConfig, EndpointStatus and EndpointMetrics, whose goose4_build_info
+	// carries the same build details as config, are those most worth restricting
+	Authorizers map[Endpoint]Authorizer
+
+	// MaintenanceToken is the bearer token required by the maintenance endpoint, which
+	// starts and ends maintenance over HTTP. Where empty, the endpoint is disabled
+	MaintenanceToken string
+
+	// DrainPeriod is how long BeginShutdown waits, having failed GTG and readyz,
+	// for load balancers to stop sending requests
+	DrainPeriod time.Duration
+
+	// DashboardRefresh is how often the dashboard, served at BasePath itself,
+	// refreshes itself. Zero disables refreshing. Unless tests are being run in
+	// the background, the dashboard reuses results for at least this long, or for
+	// DefaultDashboardRefresh where zero, so that open dashboards don't run tests
+	// any more often than they refresh
+	DashboardRefresh time.Duration
 }
 
 // NewGoose4 returns a Goose4 object to be used as net/http handler
 func NewGoose4(c Config) (g Goose4, err error) {
 	g.config = c
 	g.boot = time.Now()
+	g.tests = new(registry)
+	g.sched = new(scheduler)
+	g.flight = new(flight)
+	g.started = new(latch)
+	g.metrics = new(metrics)
+	g.maint = new(maintenance)
+	g.shut = new(shutdown)
+	g.BasePath = DefaultBasePath
+	g.DefaultTimeout = DefaultTestTimeout
+	g.DrainPeriod = DefaultDrainPeriod
+	g.DashboardRefresh = DefaultDashboardRefresh
+	g.CORS = CORS{AllowedOrigins: []string{"*"}}
 
 	return
 }
 
 // AddTest updates a Goose4 test list for healthchecks. These tests are used
-// to determine whether a service is up or not
-func (g *Goose4) AddTest(t Test) {
-	g.tests = append(g.tests, t)
+// to determine whether a service is up or not.
+//
+// Tests may be added, removed and replaced at any time, including from other goroutines
+// and after g has been mounted as a handler; where g was returned from NewGoose4, every
+// copy of g sees the change. Tests must be named, or ErrUnnamedTest is returned, and names
+// must be unique, or ErrDuplicateTest is returned. In either case the test is not added
+func (g *Goose4) AddTest(t Test) error {
+	if g.tests == nil {
+		g.tests = new(registry)
+	}
+
+	if err := g.tests.add(t); err != nil {
+		return err
+	}
+
+	g.changed()
+
+	return nil
+}
+
+// RemoveTest removes the test called name, returning ErrUnknownTest where there is none
+func (g *Goose4) RemoveTest(name string) error {
+	if g.tests == nil {
+		g.tests = new(registry)
+	}
+
+	if err := g.tests.remove(name); err != nil {
+		return err
+	}
+
+	if g.metrics != nil {
+		g.metrics.forget(name)
+	}
+
+	g.changed()
+
+	return nil
+}
+
+// ReplaceTest replaces the test with the same name as t, keeping its place in the list
+// of tests. It returns ErrUnknownTest where no test with that name has been added
+func (g *Goose4) ReplaceTest(t Test) error {
+	if g.tests == nil {
+		g.tests = new(registry)
+	}
+
+	if err := g.tests.replace(t); err != nil {
+		return err
+	}
+
+	g.changed()
+
+	return nil
+}
+
+// changed discards results cached from before the tests of g changed, so that
+// the next request runs the current set of tests
+func (g *Goose4) changed() {
+	if g.flight != nil {
+		g.flight.forget()
+	}
+
+	if g.sched != nil {
+		g.sched.forget()
+	}
 }
 
 // ServeHTTP is an http router to serve se4 endpoints
 func (g Goose4) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.serve(g.endpoint(r.URL.Path), w, r)
+}
+
+// serve responds to r with endpoint e
+func (g Goose4) serve(e Endpoint, w http.ResponseWriter, r *http.Request) {
 	var body []byte
-	var errs bool
+	var status int
 	var err error
 
-	w.Header().Set("access-control-allow-origin", "*")
-	w.Header().Set("access-control-allow-headers", "origin, content-type, accept")
-	w.Header().Set("access-control-allow-methods", "GET")
+	preflight := g.CORS.apply(e, w, r)
 
 	w.Header().Set("Content-Type", "application/json")
 
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	f, ok := routes[e]
+	authorized, challenges := g.authorized(e, r)
+
+	switch {
+	case !ok:
+		status = http.StatusNotFound
+		body, err = Error{http.StatusNotFound, fmt.Sprintf("No such route %q", r.URL.Path)}.Marshal()
+	case preflight:
+		// Preflight requests carry no credentials, and so are answered before authorization
+		status = http.StatusNoContent
+		w.Header().Del("Content-Type")
+	case !allowed(e, r.Method):
+		w.Header().Set("Allow", strings.Join(allow(e), ", "))
+		status = http.StatusMethodNotAllowed
 		body, err = Error{http.StatusMethodNotAllowed, fmt.Sprintf("Method %q not allowed", r.Method)}.Marshal()
-	} else {
-		switch r.URL.Path {
-		case "/service/config":
-			body, err = g.config.Marshal()
-		case "/service/status":
-			body, err = Status{Config: g.config}.Marshal(g.boot)
-		case "/service/healthcheck":
-			h := NewHealthcheck(g.tests)
-			body, errs, err = h.All()
-
-			if errs {
-				w.WriteHeader(http.StatusInternalServerError)
-			}
-		case "/service/healthcheck/gtg":
-			w.Header().Set("Content-Type", "text/plain")
-
-			h := NewHealthcheck(g.tests)
-			_, errs, err = h.GTG()
-
-			if errs {
-				w.WriteHeader(http.StatusInternalServerError)
-				body = []byte(`"Bad"`)
-			} else {
-				body = []byte(`"OK"`)
-			}
-
-		case "/service/healthcheck/asg":
-			w.Header().Set("Content-Type", "text/plain")
-			h := NewHealthcheck(g.tests)
-			_, errs, err = h.ASG()
-
-			if errs {
-				w.WriteHeader(http.StatusInternalServerError)
-				body = []byte(`"Bad"`)
-			} else {
-				body = []byte(`"OK"`)
-			}
-
-		default:
-			w.WriteHeader(http.StatusNotFound)
-			body, err = Error{http.StatusNotFound, fmt.Sprintf("No such route %q", r.URL.Path)}.Marshal()
+	case r.Method == http.MethodOptions:
+		w.Header().Set("Allow", strings.Join(allow(e), ", "))
+		status = http.StatusNoContent
+		w.Header().Del("Content-Type")
+	case !authorized:
+		status, body, err = refuse(w, challenges)
+	case documents[e]:
+		w.Header().Add("Vary", "Accept")
+
+		format, ok := negotiate(r)
+		if !ok {
+			status = http.StatusNotAcceptable
+			body, err = Error{http.StatusNotAcceptable, fmt.Sprintf("Format %q not supported", r.URL.Query().Get("format"))}.Marshal()
+
+			break
+		}
+
+		status, body, err = f(g, w, r)
+		if err == nil {
+			var ct string
+			body, ct, err = render(format, g.title(e), body)
+			w.Header().Set("Content-Type", ct)
 		}
+	default:
+		status, body, err = f(g, w, r)
 	}
 
 	if err != nil {
@@ -93,9 +235,81 @@ func (g Goose4) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		// This will nuke the original error; this is acceptable due to the risk of leaking
 		// potentially sensitive information otherwise
-		w.WriteHeader(http.StatusInternalServerError)
+		w.Header().Set("Content-Type", "application/json")
+		status = http.StatusInternalServerError
 		body, err = Error{http.StatusInternalServerError, fmt.Sprint("Internal error")}.Marshal()
 	}
 
-	fmt.Fprintf(w, string(body))
+	// HEAD is served just as GET, but without a body
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		body = nil
+	}
+
+	if status != 0 {
+		w.WriteHeader(status)
+	}
+
+	w.Write(body)
+}
+
+// title returns the title of endpoint e, as served in HTML
+func (g Goose4) title(e Endpoint) string {
+	name := string(e)
+	if e == EndpointDashboard {
+		name = "dashboard"
+	}
+
+	if g.config.ArtifactID == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s %s", g.config.ArtifactID, name)
+}
+
+// serveTests returns the results of tests relevant to mode, and whether any critical tests failed.
+// Where tests are being run in the background the latest results are used, otherwise tests
+// are run there and then, sharing runs with any concurrent requests
+func (g Goose4) serveTests(mode int) (Healthcheck, bool) {
+	return g.serveTestsWithin(mode, g.MinInterval)
+}
+
+// serveTestsWithin is serveTests, reusing results which finished less than minInterval ago
+func (g Goose4) serveTestsWithin(mode int, minInterval time.Duration) (Healthcheck, bool) {
+	if g.sched != nil {
+		if h, errs, ok := g.sched.cached(mode); ok {
+			return h, errs
+		}
+	}
+
+	run := func() (Healthcheck, bool) {
+		return g.runTests(mode)
+	}
+
+	if g.flight != nil {
+		return g.flight.do(mode, minInterval, run)
+	}
+
+	return run()
+}
+
+// runTests runs the tests relevant to mode, recording their results for metrics
+func (g Goose4) runTests(mode int) (Healthcheck, bool) {
+	h := g.healthcheck()
+	errs := h.runTests(context.Background(), mode)
+
+	if g.metrics != nil {
+		g.metrics.record(h.Tests)
+	}
+
+	return h, errs
+}
+
+// healthcheck returns a Healthcheck for the tests added to g
+func (g Goose4) healthcheck() Healthcheck {
+	h := NewHealthcheck(g.tests.list())
+	h.Timeout = g.DefaultTimeout
+	h.Concurrency = g.MaxConcurrency
+
+	return h
 }
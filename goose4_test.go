@@ -6,7 +6,9 @@ import (
 	"net/url"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 type rw struct {
@@ -77,16 +79,23 @@ func TestServeHTTP(t *testing.T) {
 		{"/service/healthcheck/asg", "GET", []Test{}, 200, `"OK"`, "text/plain", false},
 		{"/service/healthcheck/gtg", "GET", []Test{}, 200, `"OK"`, "text/plain", false},
 
+		{"/service/config", "HEAD", []Test{}, 200, "", "application/json", false},
+		{"/service/config", "OPTIONS", []Test{}, 204, "", "", false},
+		{"/service/healthcheck/gtg", "HEAD", []Test{{F: HealthTestFailure, RequiredForGTG: true}}, 500, "", "text/plain", false},
+
 		{"/service/config", "POST", []Test{}, 405, `{"status":405,"message":"Method \"POST\" not allowed"}`, "application/json", false},
 		{"/service/floopydoop", "GET", []Test{}, 404, `{"status":404,"message":"No such route \"/service/floopydoop\""}`, "application/json", false},
 
 		{"/service/healthcheck", "GET", []Test{{F: HealthTestFailure, RequiredForASG: true, RequiredForGTG: true}}, 500, "", "application/json", true},
 		{"/service/healthcheck/asg", "GET", []Test{{F: HealthTestFailure, RequiredForASG: true}}, 500, `"Bad"`, "text/plain", false},
 		{"/service/healthcheck/gtg", "GET", []Test{{F: HealthTestFailure, RequiredForGTG: true}}, 500, `"Bad"`, "text/plain", false},
+		{"/service/healthcheck", "GET", []Test{{F: HealthTestFailure, Severity: SeverityWarning, RequiredForGTG: true}}, 200, "", "application/json", true},
+		{"/service/healthcheck/gtg", "GET", []Test{{F: HealthTestFailure, Severity: SeverityWarning, RequiredForGTG: true}}, 200, `"OK"`, "text/plain", false},
+		{"/service/healthcheck/gtg", "GET", []Test{{Check: HealthCheckHang, Timeout: time.Millisecond, RequiredForGTG: true}}, 500, `"Bad"`, "text/plain", false},
 	} {
 		t.Run(fmt.Sprintf("%s %s", test.method, test.path), func(t *testing.T) {
 			g, _ := NewGoose4(Config{})
-			g.tests = test.tests
+			g.tests = &registry{tests: test.tests}
 			w := newrw()
 			r := &http.Request{
 				Method: test.method,
@@ -116,6 +125,66 @@ func TestServeHTTP(t *testing.T) {
 	}
 }
 
+func TestServeHTTPAllow(t *testing.T) {
+	for _, test := range []struct {
+		method              string
+		path                string
+		expectStatusCode    int
+		expectAllow         string
+		expectContentLength string
+	}{
+		{"GET", "/service/config", 200, "", ""},
+		{"HEAD", "/service/config", 200, "", "171"},
+		{"HEAD", "/service/healthcheck/gtg", 200, "", "4"},
+		{"OPTIONS", "/service/config", 204, "GET, HEAD, OPTIONS", ""},
+		{"OPTIONS", "/service/maintenance", 204, "GET, HEAD, PUT, DELETE, OPTIONS", ""},
+		{"OPTIONS", "/service/floopydoop", 404, "", ""},
+		{"POST", "/service/healthcheck", 405, "GET, HEAD, OPTIONS", ""},
+		{"POST", "/service/maintenance", 405, "GET, HEAD, PUT, DELETE, OPTIONS", ""},
+		{"POST", "/service/floopydoop", 404, "", ""},
+	} {
+		t.Run(fmt.Sprintf("%s %s", test.method, test.path), func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+			w := newrw()
+			g.ServeHTTP(w, &http.Request{Method: test.method, URL: &url.URL{Path: test.path}})
+
+			if w.status != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.status)
+			}
+
+			if allow := w.headers.Get("Allow"); allow != test.expectAllow {
+				t.Errorf("expected %q, received %q", test.expectAllow, allow)
+			}
+
+			if cl := w.headers.Get("Content-Length"); cl != test.expectContentLength {
+				t.Errorf("expected %q, received %q", test.expectContentLength, cl)
+			}
+		})
+	}
+}
+
+func TestServeHTTPPanic(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.tests = &registry{tests: []Test{{Name: "panicky", F: func() bool { panic("oh no") }}}}
+
+	w := newrw()
+	g.ServeHTTP(w, &http.Request{Method: "GET", URL: &url.URL{Path: "/service/healthcheck"}})
+
+	t.Run("Status code", func(t *testing.T) {
+		if w.status != 500 {
+			t.Errorf("expected 500, received %d", w.status)
+		}
+	})
+
+	t.Run("Body text", func(t *testing.T) {
+		for _, s := range []string{`"test_result":"panicked"`, `"panic":"panic: oh no"`} {
+			if !strings.Contains(w.body, s) {
+				t.Errorf("expected %q to contain %q", w.body, s)
+			}
+		}
+	})
+}
+
 func TestAddTest(t *testing.T) {
 	for _, test := range []struct {
 		title              string
@@ -147,34 +216,36 @@ func TestAddTest(t *testing.T) {
 			}
 
 			g, _ := NewGoose4(Config{})
-			g.AddTest(t0)
+			if err := g.AddTest(t0); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
 
 			t.Run("Test Name", func(t *testing.T) {
-				if test.testName != g.tests[0].Name {
-					t.Errorf("expected %q, received %q", test.testName, g.tests[0].Name)
+				if test.testName != g.tests.tests[0].Name {
+					t.Errorf("expected %q, received %q", test.testName, g.tests.tests[0].Name)
 				}
 			})
 
 			t.Run("Test Required For ASG Value", func(t *testing.T) {
-				if test.testRequiredForASG != g.tests[0].RequiredForASG {
-					t.Errorf("expected %v, received %v", test.testRequiredForASG, g.tests[0].RequiredForASG)
+				if test.testRequiredForASG != g.tests.tests[0].RequiredForASG {
+					t.Errorf("expected %v, received %v", test.testRequiredForASG, g.tests.tests[0].RequiredForASG)
 				}
 			})
 
 			t.Run("Test Required For GTG Value", func(t *testing.T) {
-				if test.testRequiredForGTG != g.tests[0].RequiredForGTG {
-					t.Errorf("expected %v, received %v", test.testRequiredForGTG, g.tests[0].RequiredForGTG)
+				if test.testRequiredForGTG != g.tests.tests[0].RequiredForGTG {
+					t.Errorf("expected %v, received %v", test.testRequiredForGTG, g.tests.tests[0].RequiredForGTG)
 				}
 			})
 
-			// This really checks that g.tests[0] has the same name as testFunc; it doesn't
+			// This really checks that g.tests.tests[0] has the same name as testFunc; it doesn't
 			// check whether the code is the same.
 			//
 			// We can, though, be reasonably confident this is good enough: they're both
 			// anonymous functions that go internally names.
 			t.Run("Test Function", func(t *testing.T) {
 				t0Name := runtime.FuncForPC(reflect.ValueOf(test.testFunc).Pointer()).Name()
-				t1Name := runtime.FuncForPC(reflect.ValueOf(g.tests[0].F).Pointer()).Name()
+				t1Name := runtime.FuncForPC(reflect.ValueOf(g.tests.tests[0].F).Pointer()).Name()
 
 				t.Run("Valid Function", func(t *testing.T) {
 					if t0Name != t1Name {
@@ -192,7 +263,7 @@ func TestAddTest(t *testing.T) {
 				})
 
 				t.Run("Mutated Function", func(t *testing.T) {
-					g.tests[0].F = func() bool { return false }
+					g.tests.tests[0].F = func() bool { return false }
 					t0Name := runtime.FuncForPC(reflect.ValueOf(test.testFunc).Pointer()).Name()
 
 					// Validate that go isn't assigning the same name to stuff
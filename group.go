@@ -0,0 +1,44 @@
+package goose4
+
+// Group selects a set of tests by the endpoint which runs them, allowing
+// the same tests to be used outside of Goose4's own HTTP endpoints
+type Group int
+
+// Groups of tests, as run by each endpoint
+const (
+	GroupAll     Group = testAll
+	GroupASG     Group = testASGOnly
+	GroupGTG     Group = testGTGOnly
+	GroupLive    Group = testLiveOnly
+	GroupReady   Group = testReadyOnly
+	GroupStartup Group = testStartupOnly
+)
+
+// Results returns the results of the tests in group, exactly as they would be served
+// over HTTP: from the background scheduler where it is running, or otherwise by running
+// tests alongside any concurrent requests
+func (g Goose4) Results(group Group) Healthcheck {
+	h, _ := g.serveTests(int(group))
+
+	return h
+}
+
+// Tagged returns the results of those tests carrying tag, with Status
+// recalculated to match
+func (h Healthcheck) Tagged(tag string) Healthcheck {
+	tests := []Test{}
+
+	for _, t := range h.Tests {
+		for _, t0 := range t.Tags {
+			if t0 == tag {
+				tests = append(tests, t)
+				break
+			}
+		}
+	}
+
+	h.Tests = tests
+	h.Status = health(tests)
+
+	return h
+}
@@ -0,0 +1,67 @@
+package goose4
+
+import (
+	"testing"
+)
+
+func TestGoose4Results(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.tests = &registry{tests: []Test{
+		{Name: "asg", F: HealthTestFailure, RequiredForASG: true},
+		{Name: "gtg", F: HealthTestSuccess, RequiredForGTG: true},
+	}}
+
+	for _, test := range []struct {
+		group        Group
+		expectTests  int
+		expectStatus string
+	}{
+		{GroupAll, 2, HealthFailed},
+		{GroupASG, 1, HealthFailed},
+		{GroupGTG, 1, HealthOK},
+		{GroupLive, 0, HealthOK},
+	} {
+		h := g.Results(test.group)
+
+		if len(h.Tests) != test.expectTests {
+			t.Errorf("group %d: expected %d tests, received %d", test.group, test.expectTests, len(h.Tests))
+		}
+
+		if h.Status != test.expectStatus {
+			t.Errorf("group %d: expected %q, received %q", test.group, test.expectStatus, h.Status)
+		}
+	}
+}
+
+func TestHealthcheckTagged(t *testing.T) {
+	h := Healthcheck{
+		Status: HealthFailed,
+		Tests: []Test{
+			{Name: "db", Tags: []string{"storage", "orders"}, Result: ResultFailed},
+			{Name: "queue", Tags: []string{"orders"}, Result: ResultPassed},
+			{Name: "cache", Result: ResultPassed, Tags: []string{"search"}},
+		},
+	}
+
+	for _, test := range []struct {
+		tag          string
+		expectTests  int
+		expectStatus string
+	}{
+		{"orders", 2, HealthFailed},
+		{"search", 1, HealthOK},
+		{"nothing", 0, HealthOK},
+	} {
+		t.Run(test.tag, func(t *testing.T) {
+			h0 := h.Tagged(test.tag)
+
+			if len(h0.Tests) != test.expectTests {
+				t.Errorf("expected %d tests, received %d", test.expectTests, len(h0.Tests))
+			}
+
+			if h0.Status != test.expectStatus {
+				t.Errorf("expected %q, received %q", test.expectStatus, h0.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Package grpchealth serves the gRPC health checking protocol,
+https://github.com/grpc/grpc/blob/master/doc/health-checking.md, from the same tests
+goose4 uses to serve se4 endpoints, so that one set of tests serves both HTTP and gRPC probes.
+
+	se4, err := goose4.NewGoose4(c)
+	se4.AddTest(someTest)
+
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, grpchealth.NewServer(se4, map[string]grpchealth.Service{
+	    "orders.v1.Orders": {Tag: "orders"},
+	}))
+*/
+package grpchealth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebox/goose4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWatchInterval is how often a Server returned by NewServer checks for
+// changes in health while a client is watching
+const DefaultWatchInterval = 5 * time.Second
+
+// Service selects the tests which determine the health of a gRPC service; those tests
+// carrying Tag where it is set, otherwise those in Group
+type Service struct {
+	Group goose4.Group
+	Tag   string
+}
+
+// Server implements grpc.health.v1.Health, reporting services as serving unless any
+// of their critical tests fail
+type Server struct {
+	healthpb.UnimplementedHealthServer
+
+	g        goose4.Goose4
+	services map[string]Service
+
+	// WatchInterval is how often health is checked for changes while a client is watching
+	WatchInterval time.Duration
+}
+
+// NewServer returns a Server reporting on the tests of g, with services mapping the
+// names of gRPC services to the tests which determine their health. Unless otherwise
+// mapped, the overall health of the server, requested with an empty service name,
+// is determined by all tests
+func NewServer(g goose4.Goose4, services map[string]Service) *Server {
+	s := &Server{
+		g:             g,
+		services:      map[string]Service{"": {Group: goose4.GroupAll}},
+		WatchInterval: DefaultWatchInterval,
+	}
+
+	for name, svc := range services {
+		s.services[name] = svc
+	}
+
+	return s
+}
+
+// servingStatus runs, or retrieves cached results of, the tests for svc
+func (s *Server) servingStatus(svc Service) healthpb.HealthCheckResponse_ServingStatus {
+	var h goose4.Healthcheck
+	if svc.Tag != "" {
+		h = s.g.Results(goose4.GroupAll).Tagged(svc.Tag)
+	} else {
+		h = s.g.Results(svc.Group)
+	}
+
+	if h.Status == goose4.HealthFailed {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+// Check returns the health of the requested service, or NOT_FOUND where the
+// service is not known
+func (s *Server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	svc, ok := s.services[req.GetService()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+
+	// Tests may outlive the deadline of the request; there's no sense in making
+	// the client wait for them
+	done := make(chan healthpb.HealthCheckResponse_ServingStatus, 1)
+	go func() {
+		done <- s.servingStatus(svc)
+	}()
+
+	select {
+	case st := <-done:
+		return &healthpb.HealthCheckResponse{Status: st}, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// List returns the health of every known service
+func (s *Server) List(ctx context.Context, req *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	resp := &healthpb.HealthListResponse{
+		Statuses: make(map[string]*healthpb.HealthCheckResponse, len(s.services)),
+	}
+
+	for name, svc := range s.services {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		resp.Statuses[name] = &healthpb.HealthCheckResponse{Status: s.servingStatus(svc)}
+	}
+
+	return resp, nil
+}
+
+// Watch sends the health of the requested service, and again whenever it changes, until
+// the client goes away. Unknown services are reported as SERVICE_UNKNOWN
+func (s *Server) Watch(req *healthpb.HealthCheckRequest, stream grpc.ServerStreamingServer[healthpb.HealthCheckResponse]) error {
+	svc, ok := s.services[req.GetService()]
+	if !ok {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVICE_UNKNOWN}); err != nil {
+			return err
+		}
+
+		// Services are fixed when a Server is created, so an unknown service
+		// stays unknown until the client gives up
+		<-stream.Context().Done()
+
+		return status.FromContextError(stream.Context().Err()).Err()
+	}
+
+	interval := s.WatchInterval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		if st := s.servingStatus(svc); st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-t.C:
+		}
+	}
+}
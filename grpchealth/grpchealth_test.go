@@ -0,0 +1,143 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebox/goose4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var healthy int32
+
+func newClient(t *testing.T) healthpb.HealthClient {
+	g, _ := goose4.NewGoose4(goose4.Config{})
+	g.AddTest(goose4.Test{Name: "db", Tags: []string{"orders"}, RequiredForGTG: true, F: func() bool { return atomic.LoadInt32(&healthy) == 1 }})
+	g.AddTest(goose4.Test{Name: "cache", Tags: []string{"search"}, RequiredForASG: true, F: func() bool { return true }})
+
+	srv := NewServer(g, map[string]Service{
+		"orders": {Tag: "orders"},
+		"search": {Tag: "search"},
+		"asg":    {Group: goose4.GroupASG},
+	})
+	srv.WatchInterval = 5 * time.Millisecond
+
+	l := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, srv)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestServerCheck(t *testing.T) {
+	c := newClient(t)
+	atomic.StoreInt32(&healthy, 0)
+
+	for _, test := range []struct {
+		service      string
+		expectStatus healthpb.HealthCheckResponse_ServingStatus
+		expectCode   codes.Code
+	}{
+		{"", healthpb.HealthCheckResponse_NOT_SERVING, codes.OK},
+		{"orders", healthpb.HealthCheckResponse_NOT_SERVING, codes.OK},
+		{"search", healthpb.HealthCheckResponse_SERVING, codes.OK},
+		{"asg", healthpb.HealthCheckResponse_SERVING, codes.OK},
+		{"nonesuch", healthpb.HealthCheckResponse_UNKNOWN, codes.NotFound},
+	} {
+		t.Run(test.service, func(t *testing.T) {
+			resp, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: test.service})
+
+			if code := status.Code(err); code != test.expectCode {
+				t.Errorf("expected %v, received %v", test.expectCode, code)
+			}
+
+			if resp.GetStatus() != test.expectStatus {
+				t.Errorf("expected %v, received %v", test.expectStatus, resp.GetStatus())
+			}
+		})
+	}
+}
+
+func TestServerList(t *testing.T) {
+	c := newClient(t)
+	atomic.StoreInt32(&healthy, 1)
+
+	resp, err := c.List(context.Background(), &healthpb.HealthListRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, name := range []string{"", "orders", "search", "asg"} {
+		if st := resp.GetStatuses()[name].GetStatus(); st != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("%q: expected SERVING, received %v", name, st)
+		}
+	}
+}
+
+func TestServerWatch(t *testing.T) {
+	c := newClient(t)
+	atomic.StoreInt32(&healthy, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Known service", func(t *testing.T) {
+		stream, err := c.Watch(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		for i, expect := range []healthpb.HealthCheckResponse_ServingStatus{
+			healthpb.HealthCheckResponse_SERVING,
+			healthpb.HealthCheckResponse_NOT_SERVING,
+		} {
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if resp.GetStatus() != expect {
+				t.Errorf("expected %v, received %v", expect, resp.GetStatus())
+			}
+
+			if i == 0 {
+				atomic.StoreInt32(&healthy, 0)
+			}
+		}
+	})
+
+	t.Run("Unknown service", func(t *testing.T) {
+		stream, err := c.Watch(ctx, &healthpb.HealthCheckRequest{Service: "nonesuch"})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVICE_UNKNOWN {
+			t.Errorf("expected SERVICE_UNKNOWN, received %v", resp.GetStatus())
+		}
+	})
+}
@@ -1,18 +1,37 @@
 package goose4
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
 	"time"
 )
 
+// Results a Test may report once run
+const (
+	ResultPassed   = "passed"
+	ResultFailed   = "failed"
+	ResultTimedOut = "timed_out"
+	ResultPanicked = "panicked"
+	ResultWarning  = "warning"
+)
+
+var (
+	errTestFailed = errors.New("test failed")
+	errNoTestFunc = errors.New("test has no Probe, Check or F set")
+)
+
 // Test provides a way of having an API pass it's own healthcheck tests,
 // https://github.com/beamly/SE4/blob/master/SE4.md#healthcheck)
 // into goose4 to be run for the `/healthcheck/` endpoints. These are run in parallel
 // and so tests which rely on one another/ sequentialness are not allowed
 type Test struct {
-	// A simple name to help identify tests from one another
-	// there is no enforcement of uniqueness- it is left to the developer
-	// to ensure these names make sense
+	// A simple name to help identify tests from one another. Names must be
+	// unique within a Goose4, as tests are removed and replaced by name
 	Name string `json:"test_name"`
 
 	// RequiredForASG toggles whether the result of this Test is taken into account when checking ASG status
@@ -21,35 +40,207 @@ type Test struct {
 	// RequiredForGTG toggles whether the result of this Test is taken into account when checking GTG status
 	RequiredForGTG bool `json:"-"`
 
+	// RequiredForLive, RequiredForReady and RequiredForStartup toggle whether the result of this
+	// Test is taken into account by the kubernetes style livez, readyz and startupz probes
+	RequiredForLive    bool `json:"-"`
+	RequiredForReady   bool `json:"-"`
+	RequiredForStartup bool `json:"-"`
+
+	// Tags are arbitrary labels used to select tests outside of the groups
+	// run by each endpoint, such as for gRPC health checks
+	Tags []string `json:"-"`
+
+	// Severity determines how a failure of this Test affects health. Only critical
+	// tests, the default, are taken into account for ASG and GTG
+	Severity Severity `json:"severity,omitempty"`
+
 	// F is a function which returns true for successful or false for a failure
 	F func() bool `json:"-"`
 
+	// Check is a context aware alternative to F which returns nil for success. The context
+	// is cancelled when the test runs out of time, so checks should pass it on to anything
+	// which may block. Where both are set, Check is used in preference to F
+	Check func(ctx context.Context) error `json:"-"`
+
+	// Probe is like Check, but returns an Outcome so that a test may explain
+	// its result with a message and details. It is preferred over both Check and F
+	Probe func(ctx context.Context) Outcome `json:"-"`
+
+	// Timeout is how long this Test may run before being reported as timed out.
+	// A zero value falls back to the default timeout of the Healthcheck running it
+	Timeout time.Duration `json:"-"`
+
 	// The following are overwritten on whatsit
-	Result   string    `json:"test_result"`
-	Duration string    `json:"duration_millis"`
-	TestTime time.Time `json:"tested_at"`
+	Result   string        `json:"test_result"`
+	Duration time.Duration `json:"duration_millis"`
+	TestTime time.Time     `json:"tested_at"`
+
+	// Message and Details are taken from the Outcome of a test, or the error
+	// returned by Check. They explain why a test ended up with its result
+	Message string                 `json:"test_message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+
+	// Children are the results of the tests making up a composite test, such
+	// as those created by AllOf, AnyOf and Quorum
+	Children []Test `json:"children,omitempty"`
+
+	// Age is how old a result is when served from the cache of a Goose4
+	// running tests in the background
+	Age time.Duration `json:"result_age_millis,omitempty"`
+
+	// Panic and Stack are set when a test panics, rather than allowing it
+	// to crash the service. Stack is logged, but never served
+	Panic string `json:"panic,omitempty"`
+	Stack []byte `json:"-"`
+
+	// warned is set where a test passed with a warning, by way of Outcome.Warning,
+	// rather than failed with a Severity of SeverityWarning
+	warned bool
 }
 
-func (t *Test) run() bool {
+// Outcome is the result of a Probe. Alongside success or failure it carries
+// a message and any details which may help explain the result
+type Outcome struct {
+	// Err is nil where a test passed
+	Err error
+
+	// Message briefly explains the outcome. Where empty, and Err is not, the
+	// text of Err is used instead
+	Message string
+
+	// Details holds arbitrary data to be reported alongside the test result
+	Details map[string]interface{}
+
+	// children are the results of the tests making up a composite test
+	children []Test
+
+	// Warning reports a failure as a warning, which degrades rather than fails health,
+	// regardless of the Severity of the Test. This allows a test to warn of trouble
+	// ahead, such as a certificate nearing expiry, before failing outright
+	Warning bool
+}
+
+// probe returns the function used to run a Test, adapting Check or F where Probe is unset
+func (t Test) probe() func(context.Context) Outcome {
+	switch {
+	case t.Probe != nil:
+		return t.Probe
+	case t.Check != nil:
+		return func(ctx context.Context) Outcome {
+			return Outcome{Err: t.Check(ctx)}
+		}
+	case t.F != nil:
+		return func(context.Context) Outcome {
+			if t.F() {
+				return Outcome{}
+			}
+			return Outcome{Err: errTestFailed}
+		}
+	}
+
+	return func(context.Context) Outcome {
+		return Outcome{Err: errNoTestFunc}
+	}
+}
+
+// panicError holds the value recovered from a panicking test, along with
+// the stack at the point of the panic
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (p panicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+// safely calls f, turning any panic into a panicError rather than allowing
+// it to take down the process
+func safely(ctx context.Context, f func(context.Context) Outcome) (o Outcome) {
+	defer func() {
+		if r := recover(); r != nil {
+			o = Outcome{Err: panicError{r, debug.Stack()}}
+		}
+	}()
+
+	return f(ctx)
+}
+
+// run executes a Test, giving up once either the Test's own Timeout or, where that is unset,
+// timeout has passed. A timeout of zero means wait forever
+func (t *Test) run(ctx context.Context, timeout time.Duration) bool {
+	if t.Timeout > 0 {
+		timeout = t.Timeout
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	t.TestTime = time.Now()
-	success := t.F()
+	t.warned = false
+
+	// Buffered so that a check which outlives its deadline can still return
+	// without leaking a blocked goroutine
+	done := make(chan Outcome, 1)
+	go func(f func(context.Context) Outcome) {
+		done <- safely(ctx, f)
+	}(t.probe())
+
+	select {
+	case o := <-done:
+		t.Message = o.Message
+		t.Details = o.Details
+		t.Children = o.children
+
+		if p, ok := o.Err.(panicError); ok {
+			t.Result = ResultPanicked
+			t.Panic = p.Error()
+			t.Stack = p.stack
+
+			log.Printf("goose4: test %q panicked: %v\n%s", t.Name, p.value, p.stack)
+		} else if o.Err == nil {
+			t.Result = ResultPassed
+		} else if o.Warning || t.Severity == SeverityWarning {
+			t.Result = ResultWarning
+			t.warned = o.Warning
+		} else {
+			t.Result = ResultFailed
+		}
 
-	if success {
-		t.Result = "passed"
-	} else {
-		t.Result = "failed"
+		if t.Message == "" && o.Err != nil {
+			t.Message = o.Err.Error()
+		}
+	case <-ctx.Done():
+		t.Result = ResultTimedOut
+		t.Message = fmt.Sprintf("timed out after %s", time.Since(t.TestTime))
 	}
 
-	t.Duration = time.Since(t.TestTime).String()
+	t.Duration = time.Since(t.TestTime)
 
-	return success
+	return t.Result == ResultPassed
 }
 
 // Healthcheck provides a full view of healthchecks and whether they fail or not
 type Healthcheck struct {
-	ReportTime time.Time `json:"report_as_of"`
-	Duration   string    `json:"report_duration"`
-	Tests      []Test    `json:"tests"`
+	ReportTime time.Time     `json:"report_as_of"`
+	Duration   time.Duration `json:"report_duration"`
+	Status     string        `json:"status"`
+	Tests      []Test        `json:"tests"`
+
+	// Timeout is the default timeout for any Test which does not set its own
+	Timeout time.Duration `json:"-"`
+
+	// Concurrency limits how many tests are run at once. Zero runs every test at once
+	Concurrency int `json:"-"`
+
+	// Legacy serves durations as go duration strings, rather than in milliseconds
+	Legacy bool `json:"-"`
+
+	// Maintenance is the maintenance in effect when h is served, if any
+	Maintenance *Maintenance `json:"-"`
 }
 
 // NewHealthcheck creates a new Healthcheck
@@ -63,6 +254,9 @@ const (
 	testAll = iota
 	testASGOnly
 	testGTGOnly
+	testLiveOnly
+	testReadyOnly
+	testStartupOnly
 )
 
 // All runs all tests; both RequiredByGTG and RequiredByASG options are ignored
@@ -81,42 +275,63 @@ func (h *Healthcheck) ASG() (output []byte, errors bool, err error) {
 }
 
 func (h *Healthcheck) executeTests(mode int) ([]byte, bool, error) {
-	h.ReportTime = time.Now()
+	errs := h.runTests(context.Background(), mode)
+	j, err := json.Marshal(h)
+
+	return j, errs, err
+}
 
-	var errs bool
-	bchan := make(chan Test)
+// runTests runs those tests relevant to mode, replacing h.Tests with their results
+// and returning whether any critical tests failed
+func (h *Healthcheck) runTests(ctx context.Context, mode int) bool {
+	h.ReportTime = time.Now()
 
-	testList := h.getTestsByMode(mode)
+	tests := h.getTestsByMode(mode)
+	completed := make([]Test, len(tests))
 
-	if len(testList) > 0 {
-		for _, t := range testList {
-			go func(t0 Test) {
-				if !t0.run() {
-					errs = true
-				}
+	workers := len(tests)
+	if h.Concurrency > 0 && h.Concurrency < workers {
+		workers = h.Concurrency
+	}
 
-				bchan <- t0
-			}(t)
-		}
+	// Each worker writes only to the slots of those tests it takes from queue, so
+	// results stay in registration order without needing any further locking
+	queue := make(chan int)
+	var wg sync.WaitGroup
 
-		count := 1
-		completedTests := []Test{}
-		for t := range bchan {
-			completedTests = append(completedTests, t)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-			if count == len(testList) {
-				break
+			for idx := range queue {
+				t := tests[idx]
+				t.run(ctx, h.Timeout)
+				completed[idx] = t
 			}
-			count++
-		}
+		}()
+	}
 
-		h.Tests = completedTests
+	for idx := range tests {
+		queue <- idx
 	}
+	close(queue)
+	wg.Wait()
 
-	h.Duration = time.Since(h.ReportTime).String()
-	j, err := json.Marshal(h)
+	h.Tests = completed
+	h.Duration = time.Since(h.ReportTime)
+	h.Status = health(h.Tests)
 
-	return j, errs, err
+	return h.Status == HealthFailed
+}
+
+// filter returns a copy of an already run Healthcheck holding only those tests
+// relevant to mode, and whether any critical tests failed. No tests are re-run
+func (h Healthcheck) filter(mode int) (Healthcheck, bool) {
+	h.Tests = h.getTestsByMode(mode)
+	h.Status = health(h.Tests)
+
+	return h, h.Status == HealthFailed
 }
 
 func (h *Healthcheck) getTestsByMode(mode int) (filteredTests []Test) {
@@ -130,6 +345,18 @@ func (h *Healthcheck) getTestsByMode(mode int) (filteredTests []Test) {
 			if t.RequiredForGTG {
 				filteredTests = append(filteredTests, t)
 			}
+		case testLiveOnly:
+			if t.RequiredForLive {
+				filteredTests = append(filteredTests, t)
+			}
+		case testReadyOnly:
+			if t.RequiredForReady {
+				filteredTests = append(filteredTests, t)
+			}
+		case testStartupOnly:
+			if t.RequiredForStartup {
+				filteredTests = append(filteredTests, t)
+			}
 		case testAll:
 			filteredTests = append(filteredTests, t)
 		}
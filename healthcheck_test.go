@@ -1,13 +1,23 @@
 package goose4
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var (
 	HealthTestSuccess = func() bool { return true }
 	HealthTestFailure = func() bool { return false }
+
+	HealthCheckSuccess = func(context.Context) error { return nil }
+	HealthCheckFailure = func(context.Context) error { return errors.New("nope") }
+	HealthCheckHang    = func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }
 )
 
 func TestTest_Run(t *testing.T) {
@@ -22,13 +32,118 @@ func TestTest_Run(t *testing.T) {
 		t.Run(test.title, func(t *testing.T) {
 			t0 := Test{F: test.f}
 
-			_ = t0.run()
+			_ = t0.run(context.Background(), 0)
+			t.Run("Result", func(t *testing.T) {
+				if test.expectedResult != t0.Result {
+					t.Errorf("expected %q, received %q", test.expectedResult, t0.Result)
+				}
+			})
+
+		})
+	}
+}
+
+func TestTest_RunCheck(t *testing.T) {
+	for _, test := range []struct {
+		title          string
+		test           Test
+		defaultTimeout time.Duration
+		expectedResult string
+		expectSuccess  bool
+	}{
+		{"A successful check", Test{Check: HealthCheckSuccess}, 0, ResultPassed, true},
+		{"An unsuccessful check", Test{Check: HealthCheckFailure}, 0, ResultFailed, false},
+		{"Check takes precedence over F", Test{Check: HealthCheckFailure, F: HealthTestSuccess}, 0, ResultFailed, false},
+		{"A hung check with its own timeout", Test{Check: HealthCheckHang, Timeout: time.Millisecond}, 0, ResultTimedOut, false},
+		{"A hung check with a default timeout", Test{Check: HealthCheckHang}, time.Millisecond, ResultTimedOut, false},
+		{"A slow legacy test", Test{F: func() bool { time.Sleep(time.Second); return true }, Timeout: time.Millisecond}, 0, ResultTimedOut, false},
+		{"A test without a function", Test{}, 0, ResultFailed, false},
+		{"A failing warning check", Test{Check: HealthCheckFailure, Severity: SeverityWarning}, 0, ResultWarning, false},
+		{"A panicking check", Test{Check: func(context.Context) error { panic("oh no") }}, 0, ResultPanicked, false},
+		{"A panicking legacy test", Test{F: func() bool { var m map[string]bool; m["boom"] = true; return true }}, 0, ResultPanicked, false},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := test.test
+			success := t0.run(context.Background(), test.defaultTimeout)
+
+			t.Run("Result", func(t *testing.T) {
+				if test.expectedResult != t0.Result {
+					t.Errorf("expected %q, received %q", test.expectedResult, t0.Result)
+				}
+			})
+
+			t.Run("Success", func(t *testing.T) {
+				if test.expectSuccess != success {
+					t.Errorf("expected %v, received %v", test.expectSuccess, success)
+				}
+			})
+		})
+	}
+}
+
+func TestTest_RunPanic(t *testing.T) {
+	t0 := Test{Name: "panicky", Check: func(context.Context) error { panic("oh no") }}
+	_ = t0.run(context.Background(), 0)
+
+	t.Run("Panic message", func(t *testing.T) {
+		if t0.Panic != "panic: oh no" {
+			t.Errorf("expected %q, received %q", "panic: oh no", t0.Panic)
+		}
+	})
+
+	t.Run("Stack trace", func(t *testing.T) {
+		if !strings.Contains(string(t0.Stack), "TestTest_RunPanic") {
+			t.Errorf("expected stack to reference the panicking function, received %q", t0.Stack)
+		}
+	})
+}
+
+func TestTest_RunProbe(t *testing.T) {
+	for _, test := range []struct {
+		title          string
+		test           Test
+		expectedResult string
+		expectedMsg    string
+		expectedDetail map[string]interface{}
+	}{
+		{"A passing probe with details", Test{Probe: func(context.Context) Outcome {
+			return Outcome{Message: "all good", Details: map[string]interface{}{"conns": 3}}
+		}}, ResultPassed, "all good", map[string]interface{}{"conns": 3}},
+		{"A failing probe with a message", Test{Probe: func(context.Context) Outcome {
+			return Outcome{Err: errors.New("connection refused"), Message: "database down"}
+		}}, ResultFailed, "database down", nil},
+		{"A failing probe without a message", Test{Probe: func(context.Context) Outcome {
+			return Outcome{Err: errors.New("connection refused")}
+		}}, ResultFailed, "connection refused", nil},
+		{"A failing check", Test{Check: HealthCheckFailure}, ResultFailed, "nope", nil},
+		{"A warning probe", Test{Probe: func(context.Context) Outcome {
+			return Outcome{Err: errors.New("expires soon"), Warning: true}
+		}}, ResultWarning, "expires soon", nil},
+		{"A failing legacy test", Test{F: HealthTestFailure}, ResultFailed, "test failed", nil},
+		{"A passing legacy test", Test{F: HealthTestSuccess}, ResultPassed, "", nil},
+		{"Probe takes precedence over Check", Test{Check: HealthCheckFailure, Probe: func(context.Context) Outcome { return Outcome{} }}, ResultPassed, "", nil},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := test.test
+			_ = t0.run(context.Background(), 0)
+
 			t.Run("Result", func(t *testing.T) {
 				if test.expectedResult != t0.Result {
 					t.Errorf("expected %q, received %q", test.expectedResult, t0.Result)
 				}
 			})
 
+			t.Run("Message", func(t *testing.T) {
+				if test.expectedMsg != t0.Message {
+					t.Errorf("expected %q, received %q", test.expectedMsg, t0.Message)
+				}
+			})
+
+			t.Run("Details", func(t *testing.T) {
+				if !reflect.DeepEqual(test.expectedDetail, t0.Details) {
+					t.Errorf("expected %v, received %v", test.expectedDetail, t0.Details)
+				}
+			})
 		})
 	}
 }
@@ -166,3 +281,92 @@ func TestHealthcheckGTG(t *testing.T) {
 
 	}
 }
+
+func TestHealthcheckOrdering(t *testing.T) {
+	var tests []Test
+	for i := 0; i < 20; i++ {
+		// Earlier tests take longer, so complete last
+		delay := time.Duration(20-i) * time.Millisecond
+		tests = append(tests, Test{
+			Name:           fmt.Sprintf("test-%02d", i),
+			RequiredForGTG: i%2 == 0,
+			F: func() bool {
+				time.Sleep(delay)
+				return true
+			},
+		})
+	}
+
+	for _, test := range []struct {
+		title       string
+		concurrency int
+		f           func(*Healthcheck) ([]byte, bool, error)
+		expectCount int
+	}{
+		{"All, unbounded", 0, (*Healthcheck).All, 20},
+		{"All, bounded", 3, (*Healthcheck).All, 20},
+		{"GTG, unbounded", 0, (*Healthcheck).GTG, 10},
+		{"GTG, bounded", 1, (*Healthcheck).GTG, 10},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			h := NewHealthcheck(tests)
+			h.Concurrency = test.concurrency
+
+			test.f(&h)
+
+			if len(h.Tests) != test.expectCount {
+				t.Fatalf("expected %d tests, received %d", test.expectCount, len(h.Tests))
+			}
+
+			for i := 1; i < len(h.Tests); i++ {
+				if h.Tests[i-1].Name >= h.Tests[i].Name {
+					t.Errorf("expected %q before %q", h.Tests[i].Name, h.Tests[i-1].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthcheckConcurrency(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		concurrency int
+		expectMax   int32
+	}{
+		{"Unbounded", 0, 8},
+		{"Bounded", 2, 2},
+		{"Bound greater than test count", 100, 8},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			var running, max int32
+
+			f := func() bool {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+						break
+					}
+				}
+
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+
+				return true
+			}
+
+			var tests []Test
+			for i := 0; i < 8; i++ {
+				tests = append(tests, Test{F: f})
+			}
+
+			h := NewHealthcheck(tests)
+			h.Concurrency = test.concurrency
+			h.All()
+
+			if max != test.expectMax {
+				t.Errorf("expected at most %d tests at once, received %d", test.expectMax, max)
+			}
+		})
+	}
+}
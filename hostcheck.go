@@ -0,0 +1,142 @@
+package goose4
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/mem"
+)
+
+// errFDUnsupported is returned by fdUsage where file descriptors can't be counted
+var errFDUnsupported = errors.New("file descriptor usage is not supported on " + runtime.GOOS)
+
+// HostOptions configures the levels of resource usage at which a host check warns, and at
+// which it fails, and how long it may take, such as to read a hung network filesystem
+type HostOptions struct {
+	// Warning and Critical are the levels at which the check warns and fails. A zero
+	// level is never reached
+	Warning  float64
+	Critical float64
+
+	// Timeout is set as the Timeout of the returned Test
+	Timeout time.Duration
+}
+
+// outcome compares value against opts, where value describes what has been measured
+func (opts HostOptions) outcome(what string, value float64, unit string, details map[string]interface{}) Outcome {
+	switch {
+	case opts.Critical > 0 && value >= opts.Critical:
+		return Outcome{
+			Err:     fmt.Errorf("%s at %.4g%s, above critical threshold of %.4g%s", what, value, unit, opts.Critical, unit),
+			Details: details,
+		}
+	case opts.Warning > 0 && value >= opts.Warning:
+		return Outcome{
+			Err:     fmt.Errorf("%s at %.4g%s, above warning threshold of %.4g%s", what, value, unit, opts.Warning, unit),
+			Details: details,
+			Warning: true,
+		}
+	}
+
+	return Outcome{Details: details}
+}
+
+// Sources of host resource usage, replaced in tests
+var (
+	diskUsage     = disk.UsageWithContext
+	virtualMemory = mem.VirtualMemoryWithContext
+	numGoroutine  = runtime.NumGoroutine
+	fdUsage       = processFDUsage
+)
+
+// DiskCheck returns a Test checking the percentage of space used on the filesystem holding path
+func DiskCheck(name, path string, opts HostOptions) Test {
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) Outcome {
+			u, err := diskUsage(ctx, path)
+			if err != nil {
+				return Outcome{Err: err}
+			}
+
+			return opts.outcome("disk usage", u.UsedPercent, "%", map[string]interface{}{
+				"path":         path,
+				"total_bytes":  u.Total,
+				"free_bytes":   u.Free,
+				"used_percent": u.UsedPercent,
+			})
+		},
+	}
+}
+
+// MemoryCheck returns a Test checking the percentage of system memory used
+func MemoryCheck(name string, opts HostOptions) Test {
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) Outcome {
+			v, err := virtualMemory(ctx)
+			if err != nil {
+				return Outcome{Err: err}
+			}
+
+			return opts.outcome("memory usage", v.UsedPercent, "%", map[string]interface{}{
+				"total_bytes":     v.Total,
+				"available_bytes": v.Available,
+				"used_percent":    v.UsedPercent,
+			})
+		},
+	}
+}
+
+// FileDescriptorCheck returns a Test checking the number of file descriptors open by this
+// process, as a percentage of its soft limit. Where file descriptors can't be counted, such
+// as on windows, the Test passes, saying as much
+func FileDescriptorCheck(name string, opts HostOptions) Test {
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) Outcome {
+			open, limit, err := fdUsage(ctx)
+			if errors.Is(err, errFDUnsupported) {
+				return Outcome{Message: err.Error()}
+			}
+			if err != nil {
+				return Outcome{Err: err}
+			}
+
+			// Without a limit there's nothing to run out of
+			if limit <= 0 {
+				return Outcome{Details: map[string]interface{}{"open": open}}
+			}
+
+			used := float64(open) / float64(limit) * 100
+
+			return opts.outcome("file descriptor usage", used, "%", map[string]interface{}{
+				"open":         open,
+				"limit":        limit,
+				"used_percent": used,
+			})
+		},
+	}
+}
+
+// GoroutineCheck returns a Test checking the number of goroutines which currently exist
+func GoroutineCheck(name string, opts HostOptions) Test {
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(context.Context) Outcome {
+			n := numGoroutine()
+
+			return opts.outcome("goroutine count", float64(n), "", map[string]interface{}{
+				"goroutines": n,
+			})
+		},
+	}
+}
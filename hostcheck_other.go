@@ -0,0 +1,10 @@
+//go:build !unix
+
+package goose4
+
+import "context"
+
+// processFDUsage returns errFDUnsupported, as file descriptors are only counted on unix
+func processFDUsage(context.Context) (open, limit int64, err error) {
+	return 0, 0, errFDUnsupported
+}
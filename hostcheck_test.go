@@ -0,0 +1,150 @@
+package goose4
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/mem"
+)
+
+func TestHostOptionsOutcome(t *testing.T) {
+	th := HostOptions{Warning: 80, Critical: 90}
+
+	for _, test := range []struct {
+		title         string
+		th            HostOptions
+		value         float64
+		expectErr     bool
+		expectWarning bool
+		expectMsg     string
+	}{
+		{"Below thresholds", th, 50, false, false, ""},
+		{"At warning threshold", th, 80, true, true, "disk usage at 80%, above warning threshold of 80%"},
+		{"Above critical threshold", th, 95.5, true, false, "disk usage at 95.5%, above critical threshold of 90%"},
+		{"No thresholds", HostOptions{}, 100, false, false, ""},
+		{"Critical threshold only", HostOptions{Critical: 90}, 85, false, false, ""},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			o := test.th.outcome("disk usage", test.value, "%", nil)
+
+			if (o.Err != nil) != test.expectErr {
+				t.Errorf("expected error %v, received %v", test.expectErr, o.Err)
+			}
+
+			if o.Warning != test.expectWarning {
+				t.Errorf("expected warning %v, received %v", test.expectWarning, o.Warning)
+			}
+
+			if o.Err != nil && o.Err.Error() != test.expectMsg {
+				t.Errorf("expected %q, received %q", test.expectMsg, o.Err.Error())
+			}
+		})
+	}
+}
+
+func TestHostChecks(t *testing.T) {
+	defer func(d, v, n, f interface{}) {
+		diskUsage = d.(func(context.Context, string) (*disk.UsageStat, error))
+		virtualMemory = v.(func(context.Context) (*mem.VirtualMemoryStat, error))
+		numGoroutine = n.(func() int)
+		fdUsage = f.(func(context.Context) (int64, int64, error))
+	}(diskUsage, virtualMemory, numGoroutine, fdUsage)
+
+	diskUsage = func(_ context.Context, path string) (*disk.UsageStat, error) {
+		if path == "/missing" {
+			return nil, errors.New("no such file or directory")
+		}
+		return &disk.UsageStat{Path: path, Total: 100, Free: 15, UsedPercent: 85}, nil
+	}
+	virtualMemory = func(context.Context) (*mem.VirtualMemoryStat, error) {
+		return &mem.VirtualMemoryStat{Total: 100, Available: 5, UsedPercent: 95}, nil
+	}
+	numGoroutine = func() int { return 50 }
+	fdUsage = func(context.Context) (int64, int64, error) { return 100, 1024, nil }
+
+	th := HostOptions{Warning: 80, Critical: 90}
+
+	for _, test := range []struct {
+		title          string
+		test           Test
+		expectedResult string
+	}{
+		{"Disk usage above warning", DiskCheck("disk", "/data", th), ResultWarning},
+		{"Disk usage unavailable", DiskCheck("disk", "/missing", th), ResultFailed},
+		{"Memory usage above critical", MemoryCheck("memory", th), ResultFailed},
+		{"File descriptors below thresholds", FileDescriptorCheck("fds", th), ResultPassed},
+		{"File descriptors above critical", FileDescriptorCheck("fds", HostOptions{Critical: 5}), ResultFailed},
+		{"Goroutines below thresholds", GoroutineCheck("goroutines", HostOptions{Warning: 100, Critical: 1000}), ResultPassed},
+		{"Goroutines above warning", GoroutineCheck("goroutines", HostOptions{Warning: 10, Critical: 1000}), ResultWarning},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := test.test
+			t0.run(context.Background(), 0)
+
+			if t0.Result != test.expectedResult {
+				t.Errorf("expected %q, received %q (%s)", test.expectedResult, t0.Result, t0.Message)
+			}
+		})
+	}
+}
+
+func TestHostOptionsTimeout(t *testing.T) {
+	opts := HostOptions{Timeout: time.Second}
+
+	for _, test := range []Test{
+		DiskCheck("disk", "/data", opts),
+		MemoryCheck("memory", opts),
+		FileDescriptorCheck("fds", opts),
+		GoroutineCheck("goroutines", opts),
+	} {
+		if test.Timeout != opts.Timeout {
+			t.Errorf("%s: expected %s, received %s", test.Name, opts.Timeout, test.Timeout)
+		}
+	}
+}
+
+func TestFileDescriptorCheckUnsupported(t *testing.T) {
+	defer func(f func(context.Context) (int64, int64, error)) { fdUsage = f }(fdUsage)
+
+	fdUsage = func(context.Context) (int64, int64, error) { return 0, 0, errFDUnsupported }
+
+	t0 := FileDescriptorCheck("fds", HostOptions{Critical: 5})
+	t0.run(context.Background(), 0)
+
+	if t0.Result != ResultPassed {
+		t.Errorf("expected %q, received %q", ResultPassed, t0.Result)
+	}
+
+	if t0.Message != errFDUnsupported.Error() {
+		t.Errorf("expected %q, received %q", errFDUnsupported.Error(), t0.Message)
+	}
+}
+
+func TestHostChecksLive(t *testing.T) {
+	if _, _, err := fdUsage(context.Background()); errors.Is(err, errFDUnsupported) {
+		t.Skip(err)
+	}
+
+	for _, test := range []Test{
+		DiskCheck("disk", os.TempDir(), HostOptions{}),
+		MemoryCheck("memory", HostOptions{}),
+		FileDescriptorCheck("fds", HostOptions{}),
+		GoroutineCheck("goroutines", HostOptions{}),
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			test.run(context.Background(), 0)
+
+			if test.Result != ResultPassed {
+				t.Errorf("expected %q, received %q (%s)", ResultPassed, test.Result, test.Message)
+			}
+
+			if len(test.Details) == 0 {
+				t.Errorf("expected details, received none")
+			}
+		})
+	}
+}
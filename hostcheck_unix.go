@@ -0,0 +1,31 @@
+//go:build unix
+
+package goose4
+
+import (
+	"context"
+	"os"
+	"syscall"
+)
+
+// processFDUsage returns the number of file descriptors open by this process, and its soft
+// limit. They are counted from /proc where mounted, and otherwise from /dev/fd, as on darwin
+func processFDUsage(context.Context) (open, limit int64, err error) {
+	var rl syscall.Rlimit
+	if err = syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return
+	}
+
+	// An unlimited soft limit overflows into a negative one, which is treated as no limit
+	limit = int64(rl.Cur)
+
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		var entries []os.DirEntry
+		if entries, err = os.ReadDir(dir); err == nil {
+			// Less the descriptor used to read dir itself
+			return int64(len(entries)) - 1, limit, nil
+		}
+	}
+
+	return 0, 0, err
+}
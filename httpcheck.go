@@ -0,0 +1,138 @@
+package goose4
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxCheckBody is the most of a response body read by HTTPCheck when matching against it
+const maxCheckBody = 1 << 20
+
+// HTTPOptions configures the requests made, and the responses accepted, by HTTPCheck
+type HTTPOptions struct {
+	// Method is the HTTP method used for requests; GET where empty
+	Method string
+
+	// Header is sent with each request
+	Header http.Header
+
+	// MinStatus and MaxStatus bound, inclusively, the response status codes which are
+	// accepted. Where both are zero, any 2xx status is accepted
+	MinStatus int
+	MaxStatus int
+
+	// BodyContains, where set, must appear in the response body
+	BodyContains string
+
+	// BodyMatches, where set, must match the response body
+	BodyMatches *regexp.Regexp
+
+	// TLSConfig is used for https requests, allowing for private CAs or client certificates
+	TLSConfig *tls.Config
+
+	// Timeout is set as the Timeout of the returned Test
+	Timeout time.Duration
+
+	// Client, where set, is used to make requests in place of one created by HTTPCheck.
+	// TLSConfig is ignored where Client is set, as is the redirect policy below
+	Client *http.Client
+}
+
+// HTTPCheck returns a Test which requests url, passing where the response matches
+// opts. The response status code is reported in the test details.
+//
+// Redirects are followed, unless the accepted status codes include any 3xx, in which
+// case the redirect itself is matched against them
+func HTTPCheck(name, url string, opts HTTPOptions) Test {
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	min, max := opts.MinStatus, opts.MaxStatus
+	if min == 0 && max == 0 {
+		min, max = 200, 299
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+
+		if opts.TLSConfig != nil {
+			t := http.DefaultTransport.(*http.Transport).Clone()
+			t.TLSClientConfig = opts.TLSConfig
+			client.Transport = t
+		}
+
+		if min < 400 && (max == 0 || max >= 300) {
+			client.CheckRedirect = func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			}
+		}
+	}
+
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) Outcome {
+			details := map[string]interface{}{"url": url}
+
+			req, err := http.NewRequest(method, url, nil)
+			if err != nil {
+				return Outcome{Err: err, Details: details}
+			}
+			req = req.WithContext(ctx)
+
+			for k, v := range opts.Header {
+				req.Header[k] = v
+			}
+
+			// Allow the Host header to be overridden, as it is for virtual hosts behind load balancers
+			if host := opts.Header.Get("Host"); host != "" {
+				req.Host = host
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return Outcome{Err: err, Details: details}
+			}
+			// Whatever is left of the body is read, up to a limit, so that the
+			// connection may be kept alive for the next run
+			defer func() {
+				io.Copy(io.Discard, io.LimitReader(resp.Body, maxCheckBody))
+				resp.Body.Close()
+			}()
+
+			details["status_code"] = resp.StatusCode
+
+			if resp.StatusCode < min || (max > 0 && resp.StatusCode > max) {
+				return Outcome{Err: fmt.Errorf("unexpected status %d", resp.StatusCode), Details: details}
+			}
+
+			if opts.BodyContains == "" && opts.BodyMatches == nil {
+				return Outcome{Details: details}
+			}
+
+			body, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckBody))
+			if err != nil {
+				return Outcome{Err: err, Details: details}
+			}
+
+			if opts.BodyContains != "" && !strings.Contains(string(body), opts.BodyContains) {
+				return Outcome{Err: fmt.Errorf("response body does not contain %q", opts.BodyContains), Details: details}
+			}
+
+			if opts.BodyMatches != nil && !opts.BodyMatches.Match(body) {
+				return Outcome{Err: fmt.Errorf("response body does not match %q", opts.BodyMatches), Details: details}
+			}
+
+			return Outcome{Details: details}
+		},
+	}
+}
@@ -0,0 +1,142 @@
+package goose4
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPCheck(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte(`{"status":"green"}`))
+		case "/auth":
+			if r.Header.Get("Authorization") != "Bearer s3cret" || r.Method != http.MethodHead {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		case "/redirect":
+			w.WriteHeader(http.StatusNotModified)
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	for _, test := range []struct {
+		title          string
+		path           string
+		opts           HTTPOptions
+		expectedResult string
+		expectedMsg    string
+	}{
+		{"A healthy dependency", "/ok", HTTPOptions{}, ResultPassed, ""},
+		{"An unhealthy dependency", "/down", HTTPOptions{}, ResultFailed, "unexpected status 503"},
+		{"An acceptable status range", "/redirect", HTTPOptions{MinStatus: 200, MaxStatus: 399}, ResultPassed, ""},
+		{"An open ended status range", "/down", HTTPOptions{MinStatus: 200}, ResultPassed, ""},
+		{"A followed redirect", "/moved", HTTPOptions{}, ResultPassed, ""},
+		{"A redirect within the status range", "/moved", HTTPOptions{MinStatus: 300, MaxStatus: 399}, ResultPassed, ""},
+		{"A redirect outside the status range", "/moved", HTTPOptions{MinStatus: 200, MaxStatus: 301}, ResultFailed, "unexpected status 302"},
+		{"A matching body", "/ok", HTTPOptions{BodyContains: `"green"`}, ResultPassed, ""},
+		{"A mismatched body", "/ok", HTTPOptions{BodyContains: `"red"`}, ResultFailed, `response body does not contain "\"red\""`},
+		{"A matching regular expression", "/ok", HTTPOptions{BodyMatches: regexp.MustCompile(`"status":"(green|amber)"`)}, ResultPassed, ""},
+		{"A mismatched regular expression", "/ok", HTTPOptions{BodyMatches: regexp.MustCompile(`^red`)}, ResultFailed, `response body does not match "^red"`},
+		{"Headers and method", "/auth", HTTPOptions{Method: http.MethodHead, Header: http.Header{"Authorization": {"Bearer s3cret"}}}, ResultPassed, ""},
+		{"Missing headers", "/auth", HTTPOptions{}, ResultFailed, "unexpected status 401"},
+		{"A slow dependency", "/slow", HTTPOptions{Timeout: 10 * time.Millisecond}, ResultTimedOut, ""},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := HTTPCheck("dependency", s.URL+test.path, test.opts)
+			t0.run(context.Background(), 0)
+
+			if t0.Result != test.expectedResult {
+				t.Errorf("expected %q, received %q (%s)", test.expectedResult, t0.Result, t0.Message)
+			}
+
+			if test.expectedMsg != "" && t0.Message != test.expectedMsg {
+				t.Errorf("expected %q, received %q", test.expectedMsg, t0.Message)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckDetails(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	t0 := HTTPCheck("dependency", s.URL, HTTPOptions{})
+	t0.run(context.Background(), 0)
+
+	if t0.Details["status_code"] != http.StatusAccepted {
+		t.Errorf("expected %d, received %v", http.StatusAccepted, t0.Details["status_code"])
+	}
+
+	if t0.Details["url"] != s.URL {
+		t.Errorf("expected %q, received %v", s.URL, t0.Details["url"])
+	}
+}
+
+func TestHTTPCheckTLS(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	for _, test := range []struct {
+		title          string
+		opts           HTTPOptions
+		expectedResult string
+	}{
+		{"An untrusted certificate", HTTPOptions{}, ResultFailed},
+		{"A trusted certificate", HTTPOptions{TLSConfig: &tls.Config{RootCAs: s.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}}, ResultPassed},
+		{"A custom client", HTTPOptions{Client: s.Client()}, ResultPassed},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := HTTPCheck("dependency", s.URL, test.opts)
+			t0.run(context.Background(), 0)
+
+			if t0.Result != test.expectedResult {
+				t.Errorf("expected %q, received %q (%s)", test.expectedResult, t0.Result, t0.Message)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckKeepAlive(t *testing.T) {
+	var conns int32
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 512<<10))
+	}))
+	s.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	t0 := HTTPCheck("dependency", s.URL, HTTPOptions{Client: &http.Client{Transport: &http.Transport{}}})
+	for i := 0; i < 3; i++ {
+		t1 := t0
+		t1.run(context.Background(), 0)
+
+		if t1.Result != ResultPassed {
+			t.Fatalf("expected %q, received %q (%s)", ResultPassed, t1.Result, t1.Message)
+		}
+	}
+
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("expected 1 connection, received %d", n)
+	}
+}
@@ -0,0 +1,173 @@
+package goose4
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Maintenance takes a service out of its load balancer without stopping it, such as while
+// it is being deployed, by forcing its GTG and/or ASG endpoints to report "Bad"
+type Maintenance struct {
+	// GTG and ASG select which endpoints report "Bad". Where neither is set, both do
+	GTG bool
+	ASG bool
+
+	// Reason explains why the service is in maintenance
+	Reason string
+
+	// Until is when maintenance ends by itself. The zero value lasts until EndMaintenance
+	Until time.Time
+
+	// Since is when maintenance started, and is set by StartMaintenance
+	Since time.Time
+}
+
+// active returns whether m is in effect at now
+func (m *Maintenance) active(now time.Time) bool {
+	return m != nil && (m.Until.IsZero() || now.Before(m.Until))
+}
+
+// drains returns whether m forces the tests of mode to report "Bad"
+func (m *Maintenance) drains(mode int) bool {
+	if !m.active(time.Now()) {
+		return false
+	}
+
+	switch mode {
+	case testGTGOnly:
+		return m.GTG
+	case testASGOnly:
+		return m.ASG
+	}
+
+	return false
+}
+
+// maintenance holds the maintenance state of a Goose4, shared by every copy of it
+type maintenance struct {
+	sync.RWMutex
+
+	current *Maintenance
+}
+
+// StartMaintenance forces the GTG and/or ASG endpoints of g to report "Bad" until either
+// m.Until passes or EndMaintenance is called, replacing any maintenance already started
+func (g *Goose4) StartMaintenance(m Maintenance) {
+	if g.maint == nil {
+		g.maint = new(maintenance)
+	}
+
+	if !m.GTG && !m.ASG {
+		m.GTG, m.ASG = true, true
+	}
+	m.Since = time.Now()
+
+	g.maint.Lock()
+	defer g.maint.Unlock()
+
+	g.maint.current = &m
+}
+
+// EndMaintenance returns the GTG and ASG endpoints of g to reporting the results of tests
+func (g *Goose4) EndMaintenance() {
+	if g.maint == nil {
+		return
+	}
+
+	g.maint.Lock()
+	defer g.maint.Unlock()
+
+	g.maint.current = nil
+}
+
+// Maintenance returns the maintenance currently in effect, and false where there is none
+func (g Goose4) Maintenance() (Maintenance, bool) {
+	m := g.maintenance()
+	if m == nil {
+		return Maintenance{}, false
+	}
+
+	return *m, true
+}
+
+// maintenance returns the maintenance currently in effect, or nil
+func (g Goose4) maintenance() *Maintenance {
+	if g.maint == nil {
+		return nil
+	}
+
+	g.maint.RLock()
+	defer g.maint.RUnlock()
+
+	if !g.maint.current.active(time.Now()) {
+		return nil
+	}
+
+	m := *g.maint.current
+
+	return &m
+}
+
+// maintenanceRequest is the body accepted by the maintenance endpoint. Until may
+// be given either as a time, or as a go duration string from now
+type maintenanceRequest struct {
+	GTG      bool      `json:"gtg"`
+	ASG      bool      `json:"asg"`
+	Reason   string    `json:"reason"`
+	Until    time.Time `json:"until"`
+	Duration string    `json:"duration"`
+}
+
+// serveMaintenance reports maintenance on GET, starts it on PUT and ends it on DELETE.
+// Every request must carry MaintenanceToken as a bearer token; where MaintenanceToken
+// is unset the endpoint is disabled
+func serveMaintenance(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	if g.MaintenanceToken == "" {
+		body, err := Error{http.StatusForbidden, "Maintenance endpoint disabled"}.Marshal()
+
+		return http.StatusForbidden, body, err
+	}
+
+	if a := BearerAuth(g.MaintenanceToken); !a.Authorized(r) {
+		return refuse(w, challenges(a))
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req maintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			body, err := Error{http.StatusBadRequest, fmt.Sprintf("Invalid maintenance request: %v", err)}.Marshal()
+
+			return http.StatusBadRequest, body, err
+		}
+
+		if req.Reason == "" {
+			body, err := Error{http.StatusBadRequest, "Maintenance requires a reason"}.Marshal()
+
+			return http.StatusBadRequest, body, err
+		}
+
+		m := Maintenance{GTG: req.GTG, ASG: req.ASG, Reason: req.Reason, Until: req.Until}
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil || d <= 0 {
+				body, err := Error{http.StatusBadRequest, fmt.Sprintf("Invalid maintenance duration %q", req.Duration)}.Marshal()
+
+				return http.StatusBadRequest, body, err
+			}
+
+			m.Until = time.Now().Add(d)
+		}
+
+		g.StartMaintenance(m)
+	case http.MethodDelete:
+		g.EndMaintenance()
+	}
+
+	body, err := json.Marshal(g.maintenance().view())
+
+	return 0, body, err
+}
@@ -0,0 +1,133 @@
+package goose4
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		maintenance Maintenance
+		expectGTG   int
+		expectASG   int
+	}{
+		{"Draining GTG", Maintenance{GTG: true, Reason: "deploy"}, 500, 200},
+		{"Draining ASG", Maintenance{ASG: true, Reason: "deploy"}, 200, 500},
+		{"Draining both by default", Maintenance{Reason: "deploy"}, 500, 500},
+		{"Expired maintenance", Maintenance{Reason: "deploy", Until: time.Now().Add(-time.Second)}, 200, 200},
+		{"Unexpired maintenance", Maintenance{Reason: "deploy", Until: time.Now().Add(time.Hour)}, 500, 500},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+
+			// Mount a copy, as http.Handle would, before starting maintenance
+			handler := g
+			g.StartMaintenance(test.maintenance)
+
+			for path, expect := range map[string]int{
+				"/service/healthcheck/gtg": test.expectGTG,
+				"/service/healthcheck/asg": test.expectASG,
+			} {
+				w := newrw()
+				handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+				if w.status != expect {
+					t.Errorf("%s: expected %d, received %d", path, expect, w.status)
+				}
+			}
+
+			g.EndMaintenance()
+
+			for _, path := range []string{"/service/healthcheck/gtg", "/service/healthcheck/asg"} {
+				w := newrw()
+				handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+				if w.status != 200 {
+					t.Errorf("%s: expected 200 once maintenance ended, received %d", path, w.status)
+				}
+			}
+		})
+	}
+}
+
+func TestMaintenanceReported(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.StartMaintenance(Maintenance{GTG: true, Reason: "deploying v2"})
+
+	for _, path := range []string{"/service/healthcheck", "/service/status"} {
+		w := newrw()
+		g.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		expect := `"maintenance":{"active":true,"gtg":true,"reason":"deploying v2","since":`
+		if !strings.Contains(w.body, expect) {
+			t.Errorf("%s: expected %s to contain %s", path, w.body, expect)
+		}
+	}
+
+	g.EndMaintenance()
+
+	for _, path := range []string{"/service/healthcheck", "/service/status"} {
+		w := newrw()
+		g.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		if strings.Contains(w.body, "maintenance") {
+			t.Errorf("%s: expected no maintenance, received %s", path, w.body)
+		}
+	}
+}
+
+func TestServeMaintenance(t *testing.T) {
+	for _, test := range []struct {
+		title            string
+		token            string
+		method           string
+		auth             string
+		body             string
+		expectStatusCode int
+		expectBody       string
+		expectGTG        int
+	}{
+		{"Disabled endpoint", "", "PUT", "Bearer ", `{"reason":"deploy"}`, 403, `{"status":403,"message":"Maintenance endpoint disabled"}`, 200},
+		{"Missing token", "s3cret", "PUT", "", `{"reason":"deploy"}`, 401, `{"status":401,"message":"Unauthorized"}`, 200},
+		{"Wrong token", "s3cret", "PUT", "Bearer guess", `{"reason":"deploy"}`, 401, `{"status":401,"message":"Unauthorized"}`, 200},
+		{"Starting maintenance", "s3cret", "PUT", "Bearer s3cret", `{"gtg":true,"reason":"deploy"}`, 200, `{"active":true,"gtg":true,"reason":"deploy","since":`, 500},
+		{"Starting maintenance with a duration", "s3cret", "PUT", "Bearer s3cret", `{"reason":"deploy","duration":"1h"}`, 200, `"until":`, 500},
+		{"Starting maintenance without a reason", "s3cret", "PUT", "Bearer s3cret", `{"gtg":true}`, 400, `{"status":400,"message":"Maintenance requires a reason"}`, 200},
+		{"Starting maintenance with a bad duration", "s3cret", "PUT", "Bearer s3cret", `{"reason":"deploy","duration":"soon"}`, 400, `{"status":400,"message":"Invalid maintenance duration \"soon\""}`, 200},
+		{"Starting maintenance with bad json", "s3cret", "PUT", "Bearer s3cret", `{`, 400, `Invalid maintenance request`, 200},
+		{"Reporting maintenance", "s3cret", "GET", "Bearer s3cret", "", 200, `{"active":false}`, 200},
+		{"Ending maintenance", "s3cret", "DELETE", "Bearer s3cret", "", 200, `{"active":false}`, 200},
+		{"Unsupported method", "s3cret", "POST", "Bearer s3cret", "", 405, `{"status":405,"message":"Method \"POST\" not allowed"}`, 200},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+			g.MaintenanceToken = test.token
+
+			r := httptest.NewRequest(test.method, "/service/maintenance", strings.NewReader(test.body))
+			if test.auth != "" {
+				r.Header.Set("Authorization", test.auth)
+			}
+
+			w := newrw()
+			g.ServeHTTP(w, r)
+
+			if w.status != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.status)
+			}
+
+			if !strings.Contains(w.body, test.expectBody) {
+				t.Errorf("expected %q to contain %q", w.body, test.expectBody)
+			}
+
+			w = newrw()
+			g.ServeHTTP(w, httptest.NewRequest("GET", "/service/healthcheck/gtg", nil))
+
+			if w.status != test.expectGTG {
+				t.Errorf("gtg: expected %d, received %d", test.expectGTG, w.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package goose4
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/load"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the buckets of test duration
+// histograms. They match the default buckets of the prometheus client library
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metrics records the results of tests as they are run, to be served in the
+// prometheus text exposition format,
+// https://prometheus.io/docs/instrumenting/exposition_formats/
+type metrics struct {
+	sync.Mutex
+
+	tests map[string]*testMetrics
+}
+
+// testMetrics are the metrics recorded for a single test
+type testMetrics struct {
+	severity Severity
+	passed   bool
+	lastRun  time.Time
+
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// record adds the results of tests to m
+func (m *metrics) record(tests []Test) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.tests == nil {
+		m.tests = make(map[string]*testMetrics)
+	}
+
+	for _, t := range tests {
+		tm, ok := m.tests[t.Name]
+		if !ok {
+			tm = &testMetrics{buckets: make([]uint64, len(durationBuckets))}
+			m.tests[t.Name] = tm
+		}
+
+		d := t.Duration.Seconds()
+
+		tm.severity = t.Severity
+		tm.passed = t.Result == ResultPassed
+		tm.lastRun = t.TestTime
+		tm.sum += d
+		tm.count++
+
+		for i, le := range durationBuckets {
+			if d <= le {
+				tm.buckets[i]++
+			}
+		}
+	}
+}
+
+// forget discards metrics recorded for the test called name, such as once it is removed
+func (m *metrics) forget(name string) {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.tests, name)
+}
+
+// writeTo writes recorded test metrics to buf
+func (m *metrics) writeTo(buf *bytes.Buffer) {
+	m.Lock()
+	defer m.Unlock()
+
+	names := make([]string, 0, len(m.tests))
+	for name := range m.tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return
+	}
+
+	header(buf, "goose4_test_passed", "gauge", "Whether a healthcheck test passed when it was last run.")
+	for _, name := range names {
+		tm := m.tests[name]
+		fmt.Fprintf(buf, "goose4_test_passed{test=%s,severity=%s} %s\n", quote(name), quote(tm.severity.String()), boolValue(tm.passed))
+	}
+
+	header(buf, "goose4_test_last_run_timestamp_seconds", "gauge", "When a healthcheck test was last run, as a unix timestamp.")
+	for _, name := range names {
+		fmt.Fprintf(buf, "goose4_test_last_run_timestamp_seconds{test=%s} %s\n", quote(name), timestamp(m.tests[name].lastRun))
+	}
+
+	header(buf, "goose4_test_duration_seconds", "histogram", "How long healthcheck tests take to run.")
+	for _, name := range names {
+		tm := m.tests[name]
+
+		for i, le := range durationBuckets {
+			fmt.Fprintf(buf, "goose4_test_duration_seconds_bucket{test=%s,le=%s} %d\n", quote(name), quote(float(le)), tm.buckets[i])
+		}
+		fmt.Fprintf(buf, "goose4_test_duration_seconds_bucket{test=%s,le=\"+Inf\"} %d\n", quote(name), tm.count)
+		fmt.Fprintf(buf, "goose4_test_duration_seconds_sum{test=%s} %s\n", quote(name), float(tm.sum))
+		fmt.Fprintf(buf, "goose4_test_duration_seconds_count{test=%s} %d\n", quote(name), tm.count)
+	}
+}
+
+func header(buf *bytes.Buffer, name, kind, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// quote returns a label value, quoted and escaped as per the exposition format
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func float(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func timestamp(t time.Time) string {
+	return float(float64(t.UnixNano()) / float64(time.Second))
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+
+	return "0"
+}
+
+// serveMetrics serves build information from Config, system values, and the results of
+// any tests run so far. Tests are never run by this endpoint; a scrape would otherwise
+// hit dependencies as hard as any healthcheck
+func serveMetrics(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	buf := new(bytes.Buffer)
+	c := g.config
+
+	header(buf, "goose4_build_info", "gauge", "Build information of this service, always 1.")
+	fmt.Fprintf(buf, "goose4_build_info{artifact_id=%s,build_number=%s,build_machine=%s,built_by=%s,compiler_version=%s,git_sha1=%s,version=%s} 1\n",
+		quote(c.ArtifactID), quote(c.BuildNumber), quote(c.BuildMachine), quote(c.BuiltBy), quote(c.CompilerVersion), quote(c.GitSha), quote(c.Version))
+
+	header(buf, "goose4_up_since_timestamp_seconds", "gauge", "When this service started, as a unix timestamp.")
+	fmt.Fprintf(buf, "goose4_up_since_timestamp_seconds %s\n", timestamp(g.boot))
+
+	header(buf, "goose4_uptime_seconds", "gauge", "How long this service has been running.")
+	fmt.Fprintf(buf, "goose4_uptime_seconds %s\n", float(time.Since(g.boot).Seconds()))
+
+	if l, err := load.Avg(); err == nil {
+		header(buf, "goose4_os_load", "gauge", "System load average.")
+		fmt.Fprintf(buf, "goose4_os_load{period=\"1m\"} %s\n", float(l.Load1))
+		fmt.Fprintf(buf, "goose4_os_load{period=\"5m\"} %s\n", float(l.Load5))
+		fmt.Fprintf(buf, "goose4_os_load{period=\"15m\"} %s\n", float(l.Load15))
+	}
+
+	if n, err := cpu.Counts(true); err == nil {
+		header(buf, "goose4_os_processors", "gauge", "Number of logical processors.")
+		fmt.Fprintf(buf, "goose4_os_processors %d\n", n)
+	}
+
+	header(buf, "goose4_goroutines", "gauge", "Number of goroutines which currently exist.")
+	fmt.Fprintf(buf, "goose4_goroutines %d\n", runtime.NumGoroutine())
+
+	if g.metrics != nil {
+		g.metrics.writeTo(buf)
+	}
+
+	return http.StatusOK, buf.Bytes(), nil
+}
@@ -0,0 +1,88 @@
+package goose4
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsRecord(t *testing.T) {
+	at := time.Unix(1491049800, 500000000)
+
+	m := new(metrics)
+	m.record([]Test{
+		{Name: "db", Result: ResultPassed, Duration: 20 * time.Millisecond, TestTime: at},
+		{Name: `a "quoted"\name`, Result: ResultWarning, Severity: SeverityWarning, Duration: 3 * time.Second, TestTime: at},
+	})
+	m.record([]Test{
+		{Name: "db", Result: ResultFailed, Duration: 200 * time.Millisecond, TestTime: at.Add(time.Minute)},
+	})
+
+	buf := new(bytes.Buffer)
+	m.writeTo(buf)
+
+	for _, expect := range []string{
+		"# TYPE goose4_test_passed gauge\n",
+		`goose4_test_passed{test="db",severity="critical"} 0` + "\n",
+		`goose4_test_passed{test="a \"quoted\"\\name",severity="warning"} 0` + "\n",
+		`goose4_test_last_run_timestamp_seconds{test="db"} 1.4910498605e+09` + "\n",
+		"# TYPE goose4_test_duration_seconds histogram\n",
+		`goose4_test_duration_seconds_bucket{test="db",le="0.01"} 0` + "\n",
+		`goose4_test_duration_seconds_bucket{test="db",le="0.025"} 1` + "\n",
+		`goose4_test_duration_seconds_bucket{test="db",le="0.25"} 2` + "\n",
+		`goose4_test_duration_seconds_bucket{test="db",le="+Inf"} 2` + "\n",
+		`goose4_test_duration_seconds_sum{test="db"} 0.22` + "\n",
+		`goose4_test_duration_seconds_count{test="db"} 2` + "\n",
+		`goose4_test_duration_seconds_bucket{test="a \"quoted\"\\name",le="2.5"} 0` + "\n",
+		`goose4_test_duration_seconds_bucket{test="a \"quoted\"\\name",le="5"} 1` + "\n",
+	} {
+		if !strings.Contains(buf.String(), expect) {
+			t.Errorf("expected metrics to contain %q, received:\n%s", expect, buf.String())
+		}
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	g, _ := NewGoose4(TestConfig)
+	g.tests = &registry{tests: []Test{{Name: "db", F: HealthTestSuccess}}}
+
+	t.Run("Before any tests are run", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", "/service/metrics", nil))
+
+		if w.Code != 200 {
+			t.Errorf("expected 200, received %d", w.Code)
+		}
+
+		if ct := w.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+			t.Errorf("unexpected content type %q", ct)
+		}
+
+		for _, expect := range []string{
+			`goose4_build_info{artifact_id="artifact",build_number="123",build_machine="localhost",built_by="root",compiler_version="go version go1.7.4 darwin/amd64",git_sha1="32b619ba997dfbfafd528ae3fea4e2cba8116be8",version="1.0.0"} 1`,
+			"goose4_uptime_seconds ",
+			"goose4_up_since_timestamp_seconds ",
+		} {
+			if !strings.Contains(w.Body.String(), expect) {
+				t.Errorf("expected metrics to contain %q", expect)
+			}
+		}
+
+		if strings.Contains(w.Body.String(), "goose4_test_passed") {
+			t.Errorf("expected no test metrics before tests are run")
+		}
+	})
+
+	t.Run("After tests are run", func(t *testing.T) {
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/service/healthcheck", nil))
+
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest("GET", "/service/metrics", nil))
+
+		if !strings.Contains(w.Body.String(), `goose4_test_passed{test="db",severity="critical"} 1`) {
+			t.Errorf("expected test metrics, received:\n%s", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package goose4
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// TCPOptions configures TCPCheck
+type TCPOptions struct {
+	// Timeout is set as the Timeout of the returned Test
+	Timeout time.Duration
+}
+
+// TCPCheck returns a Test which passes where a TCP connection can be made to address
+func TCPCheck(name, address string, opts TCPOptions) Test {
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) Outcome {
+			var d net.Dialer
+
+			conn, err := d.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return Outcome{Err: err}
+			}
+			conn.Close()
+
+			return Outcome{Details: map[string]interface{}{"address": address}}
+		},
+	}
+}
+
+// Resolver looks up hostnames for DNSCheck; *net.Resolver is a Resolver
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DNSOptions configures DNSCheck
+type DNSOptions struct {
+	// Resolver looks up host. Where nil, net.DefaultResolver is used
+	Resolver Resolver
+
+	// Timeout is set as the Timeout of the returned Test
+	Timeout time.Duration
+}
+
+// DNSCheck returns a Test which passes where host resolves to at least one address,
+// reporting those addresses in the test details
+func DNSCheck(name, host string, opts DNSOptions) Test {
+	r := opts.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) Outcome {
+			addrs, err := r.LookupHost(ctx, host)
+			if err != nil {
+				return Outcome{Err: err}
+			}
+
+			if len(addrs) == 0 {
+				return Outcome{Err: fmt.Errorf("%s resolved to no addresses", host)}
+			}
+
+			return Outcome{Details: map[string]interface{}{"addresses": addrs}}
+		},
+	}
+}
+
+// TLSOptions configures TLSCheck
+type TLSOptions struct {
+	// WarnBefore is how long before expiry a certificate is reported as a warning,
+	// such as 30 * 24 * time.Hour. Expired certificates always fail
+	WarnBefore time.Duration
+
+	// Config is used to connect, allowing for private CAs. Where unset, certificates
+	// are verified against the system roots for the host of the address checked
+	Config *tls.Config
+
+	// Timeout is set as the Timeout of the returned Test
+	Timeout time.Duration
+}
+
+// TLSCheck returns a Test which connects to address over TLS, warning where any of the
+// certificates presented expires within opts.WarnBefore. The earliest expiry is
+// reported in the test details
+func TLSCheck(name, address string, opts TLSOptions) Test {
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) Outcome {
+			d := tls.Dialer{Config: opts.Config}
+
+			conn, err := d.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return Outcome{Err: err}
+			}
+			defer conn.Close()
+
+			certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+			if len(certs) == 0 {
+				return Outcome{Err: errors.New("no certificates presented")}
+			}
+
+			expiring := certs[0]
+			for _, c := range certs[1:] {
+				if c.NotAfter.Before(expiring.NotAfter) {
+					expiring = c
+				}
+			}
+
+			remaining := time.Until(expiring.NotAfter)
+			details := map[string]interface{}{
+				"subject":      expiring.Subject.String(),
+				"not_after":    expiring.NotAfter,
+				"expires_in":   remaining.Round(time.Second).String(),
+				"certificates": len(certs),
+			}
+
+			switch {
+			case remaining <= 0:
+				return Outcome{Err: fmt.Errorf("certificate %q expired at %s", expiring.Subject, expiring.NotAfter), Details: details}
+			case remaining < opts.WarnBefore:
+				return Outcome{Err: fmt.Errorf("certificate %q expires in %s", expiring.Subject, remaining.Round(time.Second)), Details: details, Warning: true}
+			}
+
+			return Outcome{Details: details}
+		},
+	}
+}
@@ -0,0 +1,186 @@
+package goose4
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTCPCheck(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer l.Close()
+
+	// Grab a port nothing is listening on
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	for _, test := range []struct {
+		title          string
+		address        string
+		expectedResult string
+	}{
+		{"A listening port", l.Addr().String(), ResultPassed},
+		{"A closed port", closedAddr, ResultFailed},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := TCPCheck("tcp", test.address, TCPOptions{Timeout: time.Second})
+			t0.run(context.Background(), 0)
+
+			if t0.Result != test.expectedResult {
+				t.Errorf("expected %q, received %q (%s)", test.expectedResult, t0.Result, t0.Message)
+			}
+		})
+	}
+}
+
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+// hangingResolver never resolves anything, returning only once ctx is done
+type hangingResolver struct{}
+
+func (hangingResolver) LookupHost(ctx context.Context, _ string) ([]string, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDNSCheck(t *testing.T) {
+	r := fakeResolver{
+		"db.example.com":    {"10.0.0.1", "10.0.0.2"},
+		"empty.example.com": {},
+	}
+
+	for _, test := range []struct {
+		title          string
+		host           string
+		opts           DNSOptions
+		expectedResult string
+		expectedAddrs  interface{}
+	}{
+		{"A resolvable host", "db.example.com", DNSOptions{Resolver: r}, ResultPassed, []string{"10.0.0.1", "10.0.0.2"}},
+		{"An unknown host", "nope.example.com", DNSOptions{Resolver: r}, ResultFailed, nil},
+		{"A host without addresses", "empty.example.com", DNSOptions{Resolver: r}, ResultFailed, nil},
+		{"A slow resolver", "db.example.com", DNSOptions{Resolver: hangingResolver{}, Timeout: 10 * time.Millisecond}, ResultTimedOut, nil},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			t0 := DNSCheck("dns", test.host, test.opts)
+			t0.run(context.Background(), 0)
+
+			if t0.Result != test.expectedResult {
+				t.Errorf("expected %q, received %q (%s)", test.expectedResult, t0.Result, t0.Message)
+			}
+
+			if !reflect.DeepEqual(t0.Details["addresses"], test.expectedAddrs) {
+				t.Errorf("expected %v, received %v", test.expectedAddrs, t0.Details["addresses"])
+			}
+		})
+	}
+}
+
+// tlsServer starts a TLS server presenting a self-signed certificate which expires at notAfter
+func tlsServer(t *testing.T, notAfter time.Time) (string, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "goose4.test"},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				c.(*tls.Conn).Handshake()
+				c.Close()
+			}()
+		}
+	}()
+
+	return l.Addr().String(), pool
+}
+
+func TestTLSCheck(t *testing.T) {
+	day := 24 * time.Hour
+
+	for _, test := range []struct {
+		title          string
+		notAfter       time.Time
+		insecure       bool
+		expectedResult string
+		expectedHealth string
+	}{
+		{"A long lived certificate", time.Now().Add(90 * day), false, ResultPassed, HealthOK},
+		{"A certificate nearing expiry", time.Now().Add(10 * day), false, ResultWarning, HealthDegraded},
+		{"An expired certificate", time.Now().Add(-day), true, ResultFailed, HealthFailed},
+		{"An expired, verified certificate", time.Now().Add(-day), false, ResultFailed, HealthFailed},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			addr, pool := tlsServer(t, test.notAfter)
+
+			t0 := TLSCheck("tls", addr, TLSOptions{
+				WarnBefore: 30 * day,
+				Config:     &tls.Config{RootCAs: pool, InsecureSkipVerify: test.insecure},
+			})
+			t0.run(context.Background(), time.Second)
+
+			if t0.Result != test.expectedResult {
+				t.Errorf("expected %q, received %q (%s)", test.expectedResult, t0.Result, t0.Message)
+			}
+
+			if h := health([]Test{t0}); h != test.expectedHealth {
+				t.Errorf("expected %q, received %q", test.expectedHealth, h)
+			}
+		})
+	}
+}
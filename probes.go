@@ -0,0 +1,83 @@
+package goose4
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// latch is set once, and stays set thereafter
+type latch struct {
+	v int32
+}
+
+func (l *latch) set() {
+	atomic.StoreInt32(&l.v, 1)
+}
+
+func (l *latch) isSet() bool {
+	return l != nil && atomic.LoadInt32(&l.v) == 1
+}
+
+// probe returns a route serving a kubernetes style health probe, as per
+// https://kubernetes.io/docs/reference/using-api/health-checks/, for the tests of mode.
+// Responses are a simple "ok" unless the probe fails, or the verbose query parameter is
+// set, in which case the result of each test is listed
+func probe(name string, mode int) route {
+	return func(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		_, verbose := r.URL.Query()["verbose"]
+
+		// Once startup tests have all passed the service has started, and so
+		// there is no point in running them again
+		if mode == testStartupOnly && g.started.isSet() {
+			if verbose {
+				return http.StatusOK, []byte(fmt.Sprintf("%s check passed\n", name)), nil
+			}
+
+			return http.StatusOK, []byte("ok"), nil
+		}
+
+		// A service shutting down is never ready, whatever its tests may say
+		if mode == testReadyOnly && g.shutdown() != nil {
+			return http.StatusInternalServerError, []byte(fmt.Sprintf("[-]shutdown failed: reason withheld\n%s check failed\n", name)), nil
+		}
+
+		h, errs := g.serveTests(mode)
+
+		if mode == testStartupOnly && !errs && g.started != nil {
+			g.started.set()
+		}
+
+		if !errs && !verbose {
+			return http.StatusOK, []byte("ok"), nil
+		}
+
+		buf := new(bytes.Buffer)
+		for _, t := range h.Tests {
+			switch {
+			case t.Result == ResultPassed:
+				fmt.Fprintf(buf, "[+]%s ok\n", t.Name)
+			case t.Severity != SeverityCritical, t.Result == ResultWarning:
+				fmt.Fprintf(buf, "[+]%s %s\n", t.Name, t.Result)
+			default:
+				// Much like kubernetes, the reason is withheld for fear of leaking
+				// sensitive information to whatever can reach this probe
+				fmt.Fprintf(buf, "[-]%s failed: reason withheld\n", t.Name)
+			}
+		}
+
+		if errs {
+			fmt.Fprintf(buf, "%s check failed\n", name)
+
+			return http.StatusInternalServerError, buf.Bytes(), nil
+		}
+
+		fmt.Fprintf(buf, "%s check passed\n", name)
+
+		return http.StatusOK, buf.Bytes(), nil
+	}
+}
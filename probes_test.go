@@ -0,0 +1,97 @@
+package goose4
+
+import (
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProbes(t *testing.T) {
+	tests := []Test{
+		{Name: "live", F: HealthTestSuccess, RequiredForLive: true, RequiredForReady: true},
+		{Name: "ready", F: HealthTestFailure, RequiredForReady: true},
+		{Name: "degraded", F: HealthTestFailure, RequiredForReady: true, Severity: SeverityWarning},
+		{Name: "startup", F: HealthTestSuccess, RequiredForStartup: true},
+	}
+
+	for _, test := range []struct {
+		path             string
+		expectStatusCode int
+		expectBody       string
+	}{
+		{"/service/livez", 200, "ok"},
+		{"/service/livez?verbose", 200, "[+]live ok\nlivez check passed\n"},
+		{"/service/readyz", 500, "[+]live ok\n[-]ready failed: reason withheld\n[+]degraded warning\nreadyz check failed\n"},
+		{"/service/readyz?verbose=1", 500, "[+]live ok\n[-]ready failed: reason withheld\n[+]degraded warning\nreadyz check failed\n"},
+		{"/service/startupz", 200, "ok"},
+		{"/service/startupz?verbose", 200, "[+]startup ok\nstartupz check passed\n"},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+			g.tests = &registry{tests: tests}
+
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+
+			if w.Code != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.Code)
+			}
+
+			if w.Body.String() != test.expectBody {
+				t.Errorf("expected %q, received %q", test.expectBody, w.Body.String())
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+				t.Errorf("expected %q, received %q", "text/plain; charset=utf-8", ct)
+			}
+		})
+	}
+}
+
+func TestProbesStartupGate(t *testing.T) {
+	var ready, runs int32
+
+	g, _ := NewGoose4(Config{})
+	g.tests = &registry{tests: []Test{{
+		Name:               "warmed_up",
+		RequiredForStartup: true,
+		F: func() bool {
+			atomic.AddInt32(&runs, 1)
+			return atomic.LoadInt32(&ready) == 1
+		},
+	}}}
+
+	// The gate is shared by copies of g, such as when mounted with http.Handle
+	handler := g
+
+	for _, test := range []struct {
+		title            string
+		ready            bool
+		expectStatusCode int
+		expectRuns       int32
+	}{
+		{"Failing until startup tests pass", false, 500, 1},
+		{"Still failing", false, 500, 2},
+		{"Passing once startup tests pass", true, 200, 3},
+		{"Passing without rerunning tests", false, 200, 3},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			if test.ready {
+				atomic.StoreInt32(&ready, 1)
+			} else {
+				atomic.StoreInt32(&ready, 0)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/service/startupz", nil))
+
+			if w.Code != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.Code)
+			}
+
+			if r := atomic.LoadInt32(&runs); r != test.expectRuns {
+				t.Errorf("expected %d runs, received %d", test.expectRuns, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package goose4
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrDuplicateTest is returned when adding a Test with the same name as one already added
+	ErrDuplicateTest = errors.New("goose4: a test with this name has already been added")
+
+	// ErrUnnamedTest is returned when adding a Test without a name, as tests are
+	// removed and replaced by name
+	ErrUnnamedTest = errors.New("goose4: tests must be named")
+
+	// ErrUnknownTest is returned when removing or replacing a Test which has not been added
+	ErrUnknownTest = errors.New("goose4: no test with this name has been added")
+)
+
+// registry holds the tests added to a Goose4. It is shared by every copy of
+// a Goose4, so that tests added after mounting a handler are still run by it
+type registry struct {
+	sync.RWMutex
+
+	tests []Test
+}
+
+// list returns a copy of the tests in r, in the order they were added
+func (r *registry) list() []Test {
+	if r == nil {
+		return nil
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	tests := make([]Test, len(r.tests))
+	copy(tests, r.tests)
+
+	return tests
+}
+
+// index returns the position of the test called name, or -1 where there is none.
+// Callers must hold at least a read lock
+func (r *registry) index(name string) int {
+	for i, t := range r.tests {
+		if t.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (r *registry) add(t Test) error {
+	if t.Name == "" {
+		return ErrUnnamedTest
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.index(t.Name) >= 0 {
+		return fmt.Errorf("%w: %q", ErrDuplicateTest, t.Name)
+	}
+
+	r.tests = append(r.tests, t)
+
+	return nil
+}
+
+func (r *registry) remove(name string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	i := r.index(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrUnknownTest, name)
+	}
+
+	r.tests = append(r.tests[:i], r.tests[i+1:]...)
+
+	return nil
+}
+
+func (r *registry) replace(t Test) error {
+	r.Lock()
+	defer r.Unlock()
+
+	i := r.index(t.Name)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrUnknownTest, t.Name)
+	}
+
+	r.tests[i] = t
+
+	return nil
+}
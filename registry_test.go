@@ -0,0 +1,124 @@
+package goose4
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		change      func(g *Goose4) error
+		expectErr   error
+		expectTests []string
+	}{
+		{"Adding a test", func(g *Goose4) error { return g.AddTest(Test{Name: "c"}) }, nil, []string{"a", "b", "c"}},
+		{"Adding an unnamed test", func(g *Goose4) error { return g.AddTest(Test{F: HealthTestSuccess}) }, ErrUnnamedTest, []string{"a", "b"}},
+		{"Adding a duplicate test", func(g *Goose4) error { return g.AddTest(Test{Name: "b"}) }, ErrDuplicateTest, []string{"a", "b"}},
+		{"Removing a test", func(g *Goose4) error { return g.RemoveTest("a") }, nil, []string{"b"}},
+		{"Removing an unknown test", func(g *Goose4) error { return g.RemoveTest("c") }, ErrUnknownTest, []string{"a", "b"}},
+		{"Replacing a test", func(g *Goose4) error { return g.ReplaceTest(Test{Name: "a", RequiredForGTG: true}) }, nil, []string{"a", "b"}},
+		{"Replacing an unknown test", func(g *Goose4) error { return g.ReplaceTest(Test{Name: "c"}) }, ErrUnknownTest, []string{"a", "b"}},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+			g.AddTest(Test{Name: "a"})
+			g.AddTest(Test{Name: "b"})
+
+			err := test.change(&g)
+			if !errors.Is(err, test.expectErr) {
+				t.Errorf("expected %v, received %v", test.expectErr, err)
+			}
+
+			var names []string
+			for _, t0 := range g.tests.list() {
+				names = append(names, t0.Name)
+			}
+
+			if fmt.Sprint(names) != fmt.Sprint(test.expectTests) {
+				t.Errorf("expected %v, received %v", test.expectTests, names)
+			}
+		})
+	}
+}
+
+func TestRegistryMountedHandler(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.MinInterval = time.Hour
+
+	// Mount a copy, as http.Handle would, before adding any tests
+	handler := g
+
+	get := func() *rw {
+		w := newrw()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/service/healthcheck/gtg", nil))
+
+		return w
+	}
+
+	if w := get(); w.status != 200 {
+		t.Fatalf("expected 200 with no tests, received %d", w.status)
+	}
+
+	if err := g.AddTest(Test{Name: "db", F: HealthTestFailure, RequiredForGTG: true}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Results cached for MinInterval are discarded once tests change
+	if w := get(); w.status != 500 {
+		t.Errorf("expected 500 once a failing test is added, received %d", w.status)
+	}
+
+	if err := g.ReplaceTest(Test{Name: "db", F: HealthTestSuccess, RequiredForGTG: true}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if w := get(); w.status != 200 {
+		t.Errorf("expected 200 once the test is replaced, received %d", w.status)
+	}
+
+	if err := g.RemoveTest("db"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	w := newrw()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/service/healthcheck", nil))
+	if strings.Contains(w.body, `"db"`) {
+		t.Errorf("expected removed test to be gone, received %s", w.body)
+	}
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	handler := g
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("test-%d", i)
+			g.AddTest(Test{Name: name, F: HealthTestSuccess})
+			g.ReplaceTest(Test{Name: name, F: HealthTestFailure})
+			g.RemoveTest(name)
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			handler.ServeHTTP(newrw(), httptest.NewRequest("GET", "/service/healthcheck", nil))
+		}()
+	}
+	wg.Wait()
+
+	if tests := g.tests.list(); len(tests) != 0 {
+		t.Errorf("expected every test to have been removed, received %d", len(tests))
+	}
+}
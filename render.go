@@ -0,0 +1,370 @@
+package goose4
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats which documents, such as those of the config, status and healthcheck
+// endpoints, may be served in
+const (
+	formatJSON = "json"
+	formatText = "text"
+	formatYAML = "yaml"
+	formatHTML = "html"
+)
+
+// contentTypes are the content types served for each format
+var contentTypes = map[string]string{
+	formatJSON: "application/json",
+	formatText: "text/plain; charset=utf-8",
+	formatYAML: "application/yaml",
+	formatHTML: "text/html; charset=utf-8",
+}
+
+// formats maps the values accepted by the format query parameter, and the media
+// types of Accept headers, to formats
+var formats = map[string]string{
+	"json": formatJSON,
+	"text": formatText,
+	"txt":  formatText,
+	"yaml": formatYAML,
+	"yml":  formatYAML,
+	"html": formatHTML,
+
+	"*/*":                   formatJSON,
+	"application/*":         formatJSON,
+	"application/json":      formatJSON,
+	"text/*":                formatText,
+	"text/plain":            formatText,
+	"application/yaml":      formatYAML,
+	"application/x-yaml":    formatYAML,
+	"text/yaml":             formatYAML,
+	"text/x-yaml":           formatYAML,
+	"text/html":             formatHTML,
+	"application/xhtml+xml": formatHTML,
+}
+
+// documents are those endpoints serving documents which may be negotiated into other formats
+var documents = map[Endpoint]bool{
+	EndpointConfig:      true,
+	EndpointStatus:      true,
+	EndpointHealthcheck: true,
+}
+
+// negotiate returns the format r should be served in: that of the format query parameter
+// where set, or otherwise the most preferred format of its Accept header. JSON is served
+// where r expresses no preference. ok is false where the format parameter is unsupported
+func negotiate(r *http.Request) (format string, ok bool) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		format, ok = formats[strings.ToLower(f)]
+
+		return format, ok
+	}
+
+	format, best := formatJSON, 0.0
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, params, err := mime.ParseMediaType(accept)
+		if err != nil {
+			continue
+		}
+
+		f, ok := formats[mt]
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		// Ties go to whichever was listed first
+		if q > best {
+			format, best = f, q
+		}
+	}
+
+	return format, true
+}
+
+// render converts body, a JSON document, into format, returning the converted document
+// and its content type. Documents are converted from JSON, rather than from the values
+// they were marshalled from, so that every format holds exactly the same data
+func render(format, title string, body []byte) ([]byte, string, error) {
+	if format == formatJSON {
+		return body, contentTypes[formatJSON], nil
+	}
+
+	v, err := decode(json.NewDecoder(bytes.NewReader(body)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	buf := new(bytes.Buffer)
+
+	switch format {
+	case formatText:
+		tw := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+		writeText(tw, v, "")
+		err = tw.Flush()
+	case formatYAML:
+		writeYAML(buf, v, "")
+	case formatHTML:
+		err = pages.ExecuteTemplate(buf, "document.html", struct {
+			Title string
+			Doc   interface{}
+		}{title, v})
+	default:
+		return nil, "", fmt.Errorf("goose4: unknown format %q", format)
+	}
+
+	return buf.Bytes(), contentTypes[format], err
+}
+
+// object is a JSON object decoded with the order of its keys kept, so that
+// documents read the same in every format
+type object []field
+
+type field struct {
+	Key   string
+	Value interface{}
+}
+
+// decode reads the next value from d into an object, a []interface{}, or a scalar
+func decode(d *json.Decoder) (interface{}, error) {
+	d.UseNumber()
+
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		o := object{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := decode(d)
+			if err != nil {
+				return nil, err
+			}
+
+			o = append(o, field{k.(string), v})
+		}
+
+		_, err = d.Token()
+
+		return o, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for d.More() {
+			v, err := decode(d)
+			if err != nil {
+				return nil, err
+			}
+
+			a = append(a, v)
+		}
+
+		_, err = d.Token()
+
+		return a, err
+	}
+
+	return t, nil
+}
+
+// scalar returns v as plain text, or false where v is an object or array
+func scalar(v interface{}) (string, bool) {
+	switch v.(type) {
+	case object, []interface{}:
+		return "", false
+	case nil:
+		return "", true
+	}
+
+	return fmt.Sprint(v), true
+}
+
+// writeText writes v as aligned columns of keys and values, indenting nested values
+func writeText(w io.Writer, v interface{}, indent string) {
+	switch v := v.(type) {
+	case object:
+		for _, f := range v {
+			if s, ok := scalar(f.Value); ok {
+				fmt.Fprintf(w, "%s%s\t%s\n", indent, f.Key, s)
+				continue
+			}
+
+			fmt.Fprintf(w, "%s%s:\n", indent, f.Key)
+			writeText(w, f.Value, indent+"  ")
+		}
+	case []interface{}:
+		for i, item := range v {
+			if s, ok := scalar(item); ok {
+				fmt.Fprintf(w, "%s%s\n", indent, s)
+				continue
+			}
+
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeText(w, item, indent)
+		}
+	default:
+		s, _ := scalar(v)
+		fmt.Fprintf(w, "%s%s\n", indent, s)
+	}
+}
+
+// plainYAML matches strings which may be written in YAML without quotes, and read back as strings
+var plainYAML = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9 _./:@+-]*$`)
+
+// yamlScalar returns v as a YAML scalar. Mapping keys are written with it too, as
+// the same strings which would be misread as values would be misread as keys
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+			return strconv.Quote(v)
+		}
+
+		if !plainYAML.MatchString(v) || strings.HasSuffix(v, " ") || strings.HasSuffix(v, ":") || strings.Contains(v, ": ") {
+			return strconv.Quote(v)
+		}
+
+		return v
+	}
+
+	return fmt.Sprint(v)
+}
+
+// writeYAML writes v as YAML, with nested values indented beneath indent
+func writeYAML(w io.Writer, v interface{}, indent string) {
+	switch v := v.(type) {
+	case object:
+		if len(v) == 0 {
+			fmt.Fprintf(w, "%s{}\n", indent)
+		}
+
+		for _, f := range v {
+			writeYAMLField(w, indent+yamlScalar(f.Key)+":", f.Value, indent)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(w, "%s[]\n", indent)
+		}
+
+		for _, item := range v {
+			writeYAMLItem(w, item, indent)
+		}
+	default:
+		fmt.Fprintf(w, "%s%s\n", indent, yamlScalar(v))
+	}
+}
+
+// writeYAMLField writes the value v of a mapping key, where prefix is the key
+// and anything before it on the same line
+func writeYAMLField(w io.Writer, prefix string, v interface{}, indent string) {
+	switch v := v.(type) {
+	case object:
+		if len(v) == 0 {
+			fmt.Fprintf(w, "%s {}\n", prefix)
+			return
+		}
+
+		fmt.Fprintln(w, prefix)
+		writeYAML(w, v, indent+"  ")
+	case []interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(w, "%s []\n", prefix)
+			return
+		}
+
+		fmt.Fprintln(w, prefix)
+		writeYAML(w, v, indent)
+	default:
+		fmt.Fprintf(w, "%s %s\n", prefix, yamlScalar(v))
+	}
+}
+
+// writeYAMLItem writes v as an item of a sequence, starting on the line of its dash
+func writeYAMLItem(w io.Writer, v interface{}, indent string) {
+	o, ok := v.(object)
+	if !ok || len(o) == 0 {
+		writeYAMLField(w, indent+"-", v, indent+"  ")
+		return
+	}
+
+	for i, f := range o {
+		prefix := indent + "  " + yamlScalar(f.Key) + ":"
+		if i == 0 {
+			prefix = indent + "- " + yamlScalar(f.Key) + ":"
+		}
+
+		writeYAMLField(w, prefix, f.Value, indent+"  ")
+	}
+}
+
+// resultClass returns the CSS class of a test result or aggregate health, so that
+// passes and failures stand out from one another
+func resultClass(key string, v interface{}) string {
+	if key != "status" && key != "test_result" {
+		return ""
+	}
+
+	switch v {
+	case ResultPassed, HealthOK:
+		return "pass"
+	case ResultWarning, HealthDegraded:
+		return "warn"
+	}
+
+	return "fail"
+}
+
+// assets are the templates of the HTML pages served by Goose4. They are embedded,
+// rather than fetched from elsewhere, so that pages work wherever a service runs
+//
+//go:embed assets/*.html
+var assets embed.FS
+
+var pages = template.Must(template.New("").Funcs(template.FuncMap{
+	"class": resultClass,
+	"kind": func(v interface{}) string {
+		switch v.(type) {
+		case object:
+			return "object"
+		case []interface{}:
+			return "array"
+		}
+
+		return "scalar"
+	},
+	"text": func(v interface{}) string {
+		s, _ := scalar(v)
+
+		return s
+	},
+}).ParseFS(assets, "assets/*.html"))
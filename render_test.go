@@ -0,0 +1,161 @@
+package goose4
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	for _, test := range []struct {
+		title        string
+		query        string
+		accept       string
+		expectFormat string
+		expectOK     bool
+	}{
+		{"No preference", "", "", formatJSON, true},
+		{"Anything", "", "*/*", formatJSON, true},
+		{"Format parameter", "?format=yaml", "", formatYAML, true},
+		{"Format parameter over Accept", "?format=TXT", "text/html", formatText, true},
+		{"Unsupported format parameter", "?format=xml", "", "", false},
+		{"Plain text", "", "text/plain", formatText, true},
+		{"YAML", "", "application/x-yaml", formatYAML, true},
+		{"Browser", "", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", formatHTML, true},
+		{"Quality values", "", "text/html;q=0.5, application/yaml;q=0.9, */*;q=0.1", formatYAML, true},
+		{"Ties go to the first listed", "", "text/plain, application/json", formatText, true},
+		{"Unsupported types", "", "application/xml", formatJSON, true},
+		{"Refused types", "", "text/html;q=0", formatJSON, true},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/service/healthcheck"+test.query, nil)
+			if test.accept != "" {
+				r.Header.Set("Accept", test.accept)
+			}
+
+			format, ok := negotiate(r)
+			if format != test.expectFormat || ok != test.expectOK {
+				t.Errorf("expected %q, %v, received %q, %v", test.expectFormat, test.expectOK, format, ok)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	doc := `{"status":"failed","empty":{},"none":[],"tests":[{"test_name":"db","test_result":"passed","details":{"open":2}},{"test_name":"yes","test_result":"failed","tags":["a: b",null,true]}]}`
+
+	for _, test := range []struct {
+		format            string
+		expectBody        string
+		expectContentType string
+	}{
+		{formatJSON, doc, "application/json"},
+		{formatText, "status  failed\nempty:\nnone:\ntests:\n  test_name    db\n  test_result  passed\n  details:\n    open  2\n\n  test_name    yes\n  test_result  failed\n  tags:\n    a: b\n    \n    true\n", "text/plain; charset=utf-8"},
+		{formatYAML, "status: failed\nempty: {}\nnone: []\ntests:\n- test_name: db\n  test_result: passed\n  details:\n    open: 2\n- test_name: \"yes\"\n  test_result: failed\n  tags:\n  - \"a: b\"\n  - null\n  - true\n", "application/yaml"},
+	} {
+		t.Run(test.format, func(t *testing.T) {
+			body, ct, err := render(test.format, "healthcheck", []byte(doc))
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if string(body) != test.expectBody {
+				t.Errorf("expected %q, received %q", test.expectBody, body)
+			}
+
+			if ct != test.expectContentType {
+				t.Errorf("expected %q, received %q", test.expectContentType, ct)
+			}
+		})
+	}
+
+	t.Run(formatHTML, func(t *testing.T) {
+		body, ct, err := render(formatHTML, "orders <healthcheck>", []byte(doc))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if ct != "text/html; charset=utf-8" {
+			t.Errorf("expected %q, received %q", "text/html; charset=utf-8", ct)
+		}
+
+		for _, expect := range []string{
+			"<title>orders &lt;healthcheck&gt;</title>",
+			`<tr><th>status</th><td class="fail">failed</td></tr>`,
+			`<tr><th>test_result</th><td class="pass">passed</td></tr>`,
+			`<tr><th>open</th><td>2</td></tr>`,
+			`<li>a: b</li>`,
+		} {
+			if !strings.Contains(string(body), expect) {
+				t.Errorf("expected %s to contain %s", body, expect)
+			}
+		}
+	})
+}
+
+func TestRenderYAMLQuoting(t *testing.T) {
+	for _, test := range []struct {
+		title      string
+		doc        string
+		expectBody string
+	}{
+		{"Plain keys", `{"details":{"host":"a","open_conns":1}}`, "details:\n  host: a\n  open_conns: 1\n"},
+		{"Keys needing quotes", `{"details":{"host: port":"a","":"b","true":1,"# note":"c"}}`, "details:\n  \"host: port\": a\n  \"\": b\n  \"true\": 1\n  \"# note\": c\n"},
+		{"Values ending in a colon", `{"reason":"deploy:","tags":["a:"]}`, "reason: \"deploy:\"\ntags:\n- \"a:\"\n"},
+		{"Keys ending in a colon", `{"a:":1}`, "\"a:\": 1\n"},
+		{"Keys of sequence items", `[{"- x":"a","no":"b"}]`, "- \"- x\": a\n  \"no\": b\n"},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			body, _, err := render(formatYAML, "healthcheck", []byte(test.doc))
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if string(body) != test.expectBody {
+				t.Errorf("expected %q, received %q", test.expectBody, body)
+			}
+		})
+	}
+}
+
+func TestServeNegotiated(t *testing.T) {
+	for _, test := range []struct {
+		path              string
+		accept            string
+		expectStatusCode  int
+		expectContentType string
+		expectBody        string
+	}{
+		{"/service/config", "", 200, "application/json", `{"artifact_id":"orders"`},
+		{"/service/config?format=yaml", "", 200, "application/yaml", "artifact_id: orders\nbuild_number: \"123\"\n"},
+		{"/service/status", "text/plain", 200, "text/plain; charset=utf-8", "artifact_id       orders\n"},
+		{"/service/healthcheck", "text/html", 500, "text/html; charset=utf-8", `<td class="fail">failed</td>`},
+		{"/service/healthcheck?format=xml", "", 406, "application/json", `{"status":406,"message":"Format \"xml\" not supported"}`},
+		{"/service/healthcheck/gtg", "text/html", 500, "text/plain", `"Bad"`},
+	} {
+		t.Run(test.path+" "+test.accept, func(t *testing.T) {
+			g, _ := NewGoose4(Config{ArtifactID: "orders", BuildNumber: "123"})
+			g.AddTest(Test{Name: "db", F: HealthTestFailure, RequiredForGTG: true})
+
+			r := httptest.NewRequest("GET", test.path, nil)
+			if test.accept != "" {
+				r.Header.Set("Accept", test.accept)
+			}
+
+			w := newrw()
+			g.ServeHTTP(w, r)
+
+			if w.status != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.status)
+			}
+
+			if ct := w.headers.Get("Content-Type"); ct != test.expectContentType {
+				t.Errorf("expected %q, received %q", test.expectContentType, ct)
+			}
+
+			if !strings.Contains(w.body, test.expectBody) {
+				t.Errorf("expected %q to contain %q", w.body, test.expectBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package goose4
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// DefaultBasePath is the path under which a Goose4 returned from NewGoose4 serves
+// se4 endpoints
+const DefaultBasePath = "/service"
+
+// Endpoint identifies one of the se4 endpoints served by Goose4, by its path
+// relative to a Goose4's BasePath
+type Endpoint string
+
+// Endpoints served by Goose4
+const (
+	EndpointDashboard   Endpoint = ""
+	EndpointConfig      Endpoint = "config"
+	EndpointStatus      Endpoint = "status"
+	EndpointHealthcheck Endpoint = "healthcheck"
+	EndpointGTG         Endpoint = "healthcheck/gtg"
+	EndpointASG         Endpoint = "healthcheck/asg"
+	EndpointMetrics     Endpoint = "metrics"
+	EndpointMaintenance Endpoint = "maintenance"
+
+	EndpointLivez    Endpoint = "livez"
+	EndpointReadyz   Endpoint = "readyz"
+	EndpointStartupz Endpoint = "startupz"
+)
+
+// route serves an endpoint, returning the status code and body to respond with.
+// A status code of zero is served as 200
+type route func(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error)
+
+var routes = map[Endpoint]route{
+	EndpointDashboard:   serveDashboard,
+	EndpointConfig:      serveConfig,
+	EndpointStatus:      serveStatus,
+	EndpointHealthcheck: serveHealthcheck,
+	EndpointGTG:         serveGTG,
+	EndpointASG:         serveASG,
+	EndpointMetrics:     serveMetrics,
+	EndpointMaintenance: serveMaintenance,
+
+	EndpointLivez:    probe("livez", testLiveOnly),
+	EndpointReadyz:   probe("readyz", testReadyOnly),
+	EndpointStartupz: probe("startupz", testStartupOnly),
+}
+
+// methods are those endpoints which accept methods other than GET
+var methods = map[Endpoint][]string{
+	EndpointMaintenance: {http.MethodGet, http.MethodPut, http.MethodDelete},
+}
+
+// methodsFor returns the methods accepted by endpoint e
+func methodsFor(e Endpoint) []string {
+	if m, ok := methods[e]; ok {
+		return m
+	}
+
+	return []string{http.MethodGet}
+}
+
+// allow returns the methods accepted by endpoint e, as served in Allow headers.
+// HEAD is accepted wherever GET is, and OPTIONS everywhere
+func allow(e Endpoint) []string {
+	var m []string
+
+	for _, m0 := range methodsFor(e) {
+		m = append(m, m0)
+		if m0 == http.MethodGet {
+			m = append(m, http.MethodHead)
+		}
+	}
+
+	return append(m, http.MethodOptions)
+}
+
+// allowed returns whether endpoint e accepts method
+func allowed(e Endpoint, method string) bool {
+	for _, m := range allow(e) {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handler returns an http.Handler serving a single endpoint, regardless of the path it is
+// requested on. This allows individual endpoints to be mounted on any router, such as:
+//
+//	mux.Handle("GET /internal/gtg", se4.Handler(goose4.EndpointGTG))
+func (g Goose4) Handler(e Endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.serve(e, w, r)
+	})
+}
+
+// endpoint returns the Endpoint requested at path. Paths are matched relative to
+// BasePath where they start with it, and as they are otherwise, so that a Goose4
+// may be mounted behind http.StripPrefix
+func (g Goose4) endpoint(path string) Endpoint {
+	base := strings.TrimSuffix(g.BasePath, "/")
+	if base != "" && (path == base || strings.HasPrefix(path, base+"/")) {
+		path = path[len(base):]
+	}
+
+	return Endpoint(strings.Trim(path, "/"))
+}
+
+func serveConfig(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	body, err := g.config.Marshal()
+
+	return 0, body, err
+}
+
+func serveStatus(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	body, err := Status{
+		Config:      g.config,
+		System:      System{Legacy: g.LegacyFormat},
+		Maintenance: g.maintenance(),
+		Shutdown:    g.shutdown(),
+	}.Marshal(g.boot)
+
+	return 0, body, err
+}
+
+func serveHealthcheck(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	h, _ := g.serveTests(testAll)
+	h.Legacy = g.LegacyFormat
+	h.Maintenance = g.maintenance()
+	body, err := json.Marshal(h)
+
+	return g.StatusCodes.code(h.Status), body, err
+}
+
+func serveGTG(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	_, errs := g.serveTests(testGTGOnly)
+
+	return serveOK(w, errs || g.maintenance().drains(testGTGOnly) || g.shutdown() != nil)
+}
+
+func serveASG(g Goose4, w http.ResponseWriter, r *http.Request) (int, []byte, error) {
+	_, errs := g.serveTests(testASGOnly)
+
+	return serveOK(w, errs || g.maintenance().drains(testASGOnly))
+}
+
+// serveOK responds with a simple "OK" or, where errs is true, "Bad"
+func serveOK(w http.ResponseWriter, errs bool) (int, []byte, error) {
+	w.Header().Set("Content-Type", "text/plain")
+
+	if errs {
+		return http.StatusInternalServerError, []byte(`"Bad"`), nil
+	}
+
+	return http.StatusOK, []byte(`"OK"`), nil
+}
@@ -0,0 +1,76 @@
+package goose4
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGoose4Endpoint(t *testing.T) {
+	for _, test := range []struct {
+		basePath string
+		path     string
+		expect   Endpoint
+	}{
+		{"/service", "/service/config", EndpointConfig},
+		{"/service", "/service/healthcheck/gtg", EndpointGTG},
+		{"/service", "/service/healthcheck/gtg/", EndpointGTG},
+		{"/service/", "/service/status", EndpointStatus},
+		{"/service", "/config", EndpointConfig},
+		{"/service", "config", EndpointConfig},
+		{"/service", "/healthcheck/asg", EndpointASG},
+		{"/service", "/servicex/config", "servicex/config"},
+		{"/internal/se4", "/internal/se4/healthcheck", EndpointHealthcheck},
+		{"/internal/se4", "/service/healthcheck", "service/healthcheck"},
+		{"", "/service/config", "service/config"},
+		{"", "/config", EndpointConfig},
+	} {
+		t.Run(test.basePath+" "+test.path, func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+			g.BasePath = test.basePath
+
+			e := g.endpoint(test.path)
+			if e != test.expect {
+				t.Errorf("expected %q, received %q", test.expect, e)
+			}
+		})
+	}
+}
+
+func TestGoose4Mounting(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.tests = &registry{tests: []Test{{F: HealthTestFailure, RequiredForASG: true}}}
+
+	mux := http.NewServeMux()
+	mux.Handle("/service/", g)
+	mux.Handle("/stripped/", http.StripPrefix("/stripped", g))
+	mux.Handle("/gtg", g.Handler(EndpointGTG))
+	mux.Handle("/asg", g.Handler(EndpointASG))
+	mux.Handle("/nope", g.Handler("nope"))
+
+	for _, test := range []struct {
+		path             string
+		expectStatusCode int
+		expectBody       string
+	}{
+		{"/service/healthcheck/gtg", 200, `"OK"`},
+		{"/stripped/healthcheck/gtg", 200, `"OK"`},
+		{"/stripped/healthcheck/asg", 500, `"Bad"`},
+		{"/gtg", 200, `"OK"`},
+		{"/asg", 500, `"Bad"`},
+		{"/nope", 404, `{"status":404,"message":"No such route \"/nope\""}`},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+
+			if w.Code != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.Code)
+			}
+
+			if w.Body.String() != test.expectBody {
+				t.Errorf("expected %q, received %q", test.expectBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package goose4
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	errSchedulerRunning  = errors.New("goose4: background tests already started")
+	errSchedulerInterval = errors.New("goose4: background test interval must be positive")
+)
+
+// scheduler runs tests in the background, holding on to the latest results so
+// that they can be served without re-running tests on every request
+type scheduler struct {
+	sync.RWMutex
+
+	latest *Healthcheck
+	stop   chan struct{}
+	done   chan struct{}
+
+	// gen is incremented by forget, so that runs begun beforehand aren't cached
+	gen int
+}
+
+// Start runs all tests in the background every interval. Until Stop is called, healthcheck
+// endpoints serve the most recent results rather than running tests on each request; until
+// the first run completes, tests continue to be run on request.
+//
+// Results are discarded whenever tests are added, removed or replaced, and tests are run on
+// request until the next background run completes
+func (g *Goose4) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return errSchedulerInterval
+	}
+
+	if g.sched == nil {
+		g.sched = new(scheduler)
+	}
+	s := g.sched
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.stop != nil {
+		return errSchedulerRunning
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go s.loop(*g, interval, s.stop, s.done)
+
+	return nil
+}
+
+// Stop halts background tests started with Start, waiting for any in-flight run to
+// complete. Healthcheck endpoints go back to running tests on each request
+func (g *Goose4) Stop() {
+	s := g.sched
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.latest = nil
+	s.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+func (s *scheduler) loop(g Goose4, interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		s.RLock()
+		gen := s.gen
+		s.RUnlock()
+
+		h, _ := g.runTests(testAll)
+
+		s.Lock()
+		if s.stop == stop && s.gen == gen {
+			s.latest = &h
+		}
+		s.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// cached returns the latest results of background tests relevant to mode, with
+// each result's age set, and whether any of them failed. ok is false where there
+// are no results to serve
+func (s *scheduler) cached(mode int) (h Healthcheck, errs, ok bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if s.latest == nil {
+		return
+	}
+
+	// filter copies tests, so ages may be set without touching s.latest
+	h, errs = s.latest.filter(mode)
+	for i := range h.Tests {
+		h.Tests[i].Age = time.Since(h.Tests[i].TestTime)
+	}
+
+	return h, errs, true
+}
+
+// forget discards the latest results, such as when the tests being run have changed
+func (s *scheduler) forget() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.gen++
+	s.latest = nil
+}
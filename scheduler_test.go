@@ -0,0 +1,100 @@
+package goose4
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGoose4Start(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		interval    time.Duration
+		started     bool
+		expectError error
+	}{
+		{"A valid interval", time.Minute, false, nil},
+		{"A zero interval", 0, false, errSchedulerInterval},
+		{"A negative interval", -time.Second, false, errSchedulerInterval},
+		{"Already started", time.Minute, true, errSchedulerRunning},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			g, _ := NewGoose4(Config{})
+			defer g.Stop()
+
+			if test.started {
+				g.Start(time.Minute)
+			}
+
+			err := g.Start(test.interval)
+			if err != test.expectError {
+				t.Errorf("expected %v, received %v", test.expectError, err)
+			}
+		})
+	}
+}
+
+func TestGoose4BackgroundTests(t *testing.T) {
+	var runs int32
+
+	g, _ := NewGoose4(Config{})
+	g.tests = &registry{tests: []Test{{
+		Name:           "counted",
+		RequiredForGTG: true,
+		F: func() bool {
+			atomic.AddInt32(&runs, 1)
+			return false
+		},
+	}}}
+
+	// Mount a copy, as http.Handle would, before starting
+	handler := g
+
+	if err := g.Start(time.Hour); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Wait for the first background run to be cached
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, _, ok := g.sched.cached(testAll); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background tests did not run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, path := range []string{"/service/healthcheck", "/service/healthcheck/gtg", "/service/healthcheck"} {
+		w := newrw()
+		handler.ServeHTTP(w, &http.Request{Method: "GET", URL: &url.URL{Path: path}})
+
+		if w.status != 500 {
+			t.Errorf("%s: expected 500, received %d", path, w.status)
+		}
+
+		if path == "/service/healthcheck" && !strings.Contains(w.body, `"result_age_millis"`) {
+			t.Errorf("%s: expected result age in %q", path, w.body)
+		}
+	}
+
+	t.Run("Tests only run in background", func(t *testing.T) {
+		if r := atomic.LoadInt32(&runs); r != 1 {
+			t.Errorf("expected 1 run, received %d", r)
+		}
+	})
+
+	g.Stop()
+
+	t.Run("Tests run on request once stopped", func(t *testing.T) {
+		handler.ServeHTTP(newrw(), &http.Request{Method: "GET", URL: &url.URL{Path: "/service/healthcheck"}})
+
+		if r := atomic.LoadInt32(&runs); r != 2 {
+			t.Errorf("expected 2 runs, received %d", r)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package goose4
+
+import (
+	"fmt"
+)
+
+// Severity determines how a failing Test affects the health of a service
+type Severity int
+
+const (
+	// SeverityCritical tests fail healthchecks, along with ASG and GTG where they are
+	// required. This is the default for a Test
+	SeverityCritical Severity = iota
+
+	// SeverityWarning tests mark healthchecks as degraded, but never fail ASG or GTG
+	SeverityWarning
+
+	// SeverityInfo tests are reported, but never affect health
+	SeverityInfo
+)
+
+var severityNames = map[Severity]string{
+	SeverityCritical: "critical",
+	SeverityWarning:  "warning",
+	SeverityInfo:     "info",
+}
+
+// String returns the name of a Severity
+func (s Severity) String() string {
+	if n, ok := severityNames[s]; ok {
+		return n
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText allows a Severity to be served by name. Unknown severities are served
+// as they are printed, rather than failing the whole healthcheck
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// The aggregate health of a Healthcheck
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthFailed   = "failed"
+)
+
+// StatusCodes configures the HTTP status code served by the healthcheck endpoint
+// for each aggregate health. Zero values fall back to those of DefaultStatusCodes
+type StatusCodes struct {
+	OK       int
+	Degraded int
+	Failed   int
+}
+
+// DefaultStatusCodes serves degraded services as healthy, so that only critical
+// test failures cause an error
+var DefaultStatusCodes = StatusCodes{
+	OK:       200,
+	Degraded: 200,
+	Failed:   500,
+}
+
+// code returns the HTTP status code for health
+func (s StatusCodes) code(health string) int {
+	var c, d int
+
+	switch health {
+	case HealthOK:
+		c, d = s.OK, DefaultStatusCodes.OK
+	case HealthDegraded:
+		c, d = s.Degraded, DefaultStatusCodes.Degraded
+	default:
+		c, d = s.Failed, DefaultStatusCodes.Failed
+	}
+
+	if c == 0 {
+		return d
+	}
+
+	return c
+}
+
+// health returns the aggregate health of tests which have been run
+func health(tests []Test) string {
+	h := HealthOK
+
+	for _, t := range tests {
+		if t.Result == ResultPassed {
+			continue
+		}
+
+		switch {
+		case t.Severity == SeverityInfo:
+		case t.Severity == SeverityWarning, t.Result == ResultWarning:
+			h = HealthDegraded
+		default:
+			return HealthFailed
+		}
+	}
+
+	return h
+}
@@ -0,0 +1,100 @@
+package goose4
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSeverityMarshalText(t *testing.T) {
+	for _, test := range []struct {
+		severity Severity
+		expect   string
+	}{
+		{SeverityCritical, "critical"},
+		{SeverityWarning, "warning"},
+		{SeverityInfo, "info"},
+		{Severity(42), "Severity(42)"},
+	} {
+		t.Run(test.severity.String(), func(t *testing.T) {
+			b, err := test.severity.MarshalText()
+			if err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+
+			if string(b) != test.expect {
+				t.Errorf("expected %q, received %q", test.expect, string(b))
+			}
+		})
+	}
+}
+
+func TestServeUnknownSeverity(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.AddTest(Test{Name: "db", F: HealthTestSuccess, Severity: Severity(7)})
+
+	w := newrw()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/service/healthcheck", nil))
+
+	if w.status != 200 {
+		t.Errorf("expected 200, received %d", w.status)
+	}
+
+	if !strings.Contains(w.body, `"severity":"Severity(7)"`) {
+		t.Errorf("expected %s to contain the unknown severity", w.body)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	passed := Test{Result: ResultPassed}
+	critical := Test{Result: ResultFailed}
+	warning := Test{Result: ResultWarning, Severity: SeverityWarning}
+	warningTimeout := Test{Result: ResultTimedOut, Severity: SeverityWarning}
+	info := Test{Result: ResultFailed, Severity: SeverityInfo}
+	criticalWarning := Test{Result: ResultWarning}
+
+	for _, test := range []struct {
+		title  string
+		tests  []Test
+		expect string
+	}{
+		{"No tests", nil, HealthOK},
+		{"Passing tests", []Test{passed, passed}, HealthOK},
+		{"A failing info test", []Test{passed, info}, HealthOK},
+		{"A failing warning test", []Test{passed, warning}, HealthDegraded},
+		{"A timed out warning test", []Test{warningTimeout}, HealthDegraded},
+		{"A critical test warning", []Test{passed, criticalWarning}, HealthDegraded},
+		{"A failing critical test", []Test{passed, critical}, HealthFailed},
+		{"Failing critical and warning tests", []Test{warning, critical, info}, HealthFailed},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			h := health(test.tests)
+			if h != test.expect {
+				t.Errorf("expected %q, received %q", test.expect, h)
+			}
+		})
+	}
+}
+
+func TestStatusCodesCode(t *testing.T) {
+	for _, test := range []struct {
+		title  string
+		codes  StatusCodes
+		health string
+		expect int
+	}{
+		{"Default ok", StatusCodes{}, HealthOK, 200},
+		{"Default degraded", StatusCodes{}, HealthDegraded, 200},
+		{"Default failed", StatusCodes{}, HealthFailed, 500},
+		{"Custom degraded", StatusCodes{Degraded: 207}, HealthDegraded, 207},
+		{"Custom failed", StatusCodes{Failed: 503}, HealthFailed, 503},
+		{"Custom failed leaves ok alone", StatusCodes{Failed: 503}, HealthOK, 200},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			c := test.codes.code(test.health)
+			if c != test.expect {
+				t.Errorf("expected %d, received %d", test.expect, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package goose4
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultDrainPeriod is how long a Goose4 returned from NewGoose4 waits, once shutdown
+// has begun, for load balancers to stop sending it requests
+const DefaultDrainPeriod = 5 * time.Second
+
+// Shutdown describes a service which has begun shutting down
+type Shutdown struct {
+	// Since is when shutdown began
+	Since time.Time
+
+	// DrainPeriod is how long the service waits, from Since, before shutting down
+	DrainPeriod time.Duration
+}
+
+// shutdown holds the shutdown state of a Goose4, shared by every copy of it
+type shutdown struct {
+	sync.RWMutex
+
+	current *Shutdown
+}
+
+// BeginShutdown immediately fails the GTG and readyz endpoints of g, so that load balancers
+// stop sending requests, then waits for DrainPeriod for them to notice. It returns early,
+// with the error of ctx, should ctx be done first.
+//
+// Shutdown cannot be undone. Calling BeginShutdown again waits out the drain period
+// already begun, rather than starting another
+func (g *Goose4) BeginShutdown(ctx context.Context) error {
+	if g.shut == nil {
+		g.shut = new(shutdown)
+	}
+
+	g.shut.Lock()
+	if g.shut.current == nil {
+		g.shut.current = &Shutdown{Since: time.Now(), DrainPeriod: g.DrainPeriod}
+	}
+	s := *g.shut.current
+	g.shut.Unlock()
+
+	t := time.NewTimer(time.Until(s.Since.Add(s.DrainPeriod)))
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown begins shutdown with BeginShutdown and, once the drain period has passed, shuts
+// down srv. Should ctx be done before then, srv is shut down there and then. For instance:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	defer stop()
+//
+//	go srv.ListenAndServe()
+//	<-ctx.Done()
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//
+//	se4.Shutdown(ctx, srv)
+func (g *Goose4) Shutdown(ctx context.Context, srv *http.Server) error {
+	// Any error is that of ctx, which srv.Shutdown returns too
+	g.BeginShutdown(ctx)
+
+	return srv.Shutdown(ctx)
+}
+
+// shutdown returns the shutdown in progress, or nil
+func (g Goose4) shutdown() *Shutdown {
+	if g.shut == nil {
+		return nil
+	}
+
+	g.shut.RLock()
+	defer g.shut.RUnlock()
+
+	return g.shut.current
+}
@@ -0,0 +1,125 @@
+package goose4
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBeginShutdown(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.DrainPeriod = 200 * time.Millisecond
+	g.AddTest(Test{Name: "db", F: HealthTestSuccess, RequiredForGTG: true, RequiredForLive: true, RequiredForReady: true})
+
+	// Mount a copy, as http.Handle would, before shutting down
+	handler := g
+
+	done := make(chan error)
+	go func() {
+		done <- g.BeginShutdown(context.Background())
+	}()
+
+	// Wait for shutdown to begin
+	for g.shutdown() == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, test := range []struct {
+		path             string
+		expectStatusCode int
+		expectBody       string
+	}{
+		{"/service/healthcheck/gtg", 500, `"Bad"`},
+		{"/service/healthcheck/asg", 200, `"OK"`},
+		{"/service/readyz", 500, "[-]shutdown failed: reason withheld\nreadyz check failed\n"},
+		{"/service/livez", 200, "ok"},
+		{"/service/status", 200, `"shutdown":{"since":`},
+		{"/service/status", 200, `"drain_period_millis":200}`},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			w := newrw()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+
+			if w.status != test.expectStatusCode {
+				t.Errorf("expected %d, received %d", test.expectStatusCode, w.status)
+			}
+
+			if !strings.Contains(w.body, test.expectBody) {
+				t.Errorf("expected %q to contain %q", w.body, test.expectBody)
+			}
+		})
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected BeginShutdown to wait out the drain period, returned %v", err)
+	default:
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if time.Since(g.shutdown().Since) < g.DrainPeriod {
+		t.Errorf("expected BeginShutdown to wait at least %s", g.DrainPeriod)
+	}
+}
+
+func TestBeginShutdownCancelled(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.DrainPeriod = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := g.BeginShutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, received %v", context.DeadlineExceeded, err)
+	}
+
+	w := newrw()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/service/healthcheck/gtg", nil))
+
+	if w.status != 500 {
+		t.Errorf("expected shutdown to remain in progress, received %d", w.status)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	g, _ := NewGoose4(Config{})
+	g.DrainPeriod = 200 * time.Millisecond
+
+	s := httptest.NewUnstartedServer(g)
+	s.Start()
+	defer s.Close()
+
+	done := make(chan error)
+	go func() {
+		done <- g.Shutdown(context.Background(), s.Config)
+	}()
+
+	for g.shutdown() == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	// During the drain period the server still serves requests, failing GTG
+	resp, err := http.Get(s.URL + "/service/healthcheck/gtg")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 500 {
+		t.Errorf("expected 500, received %d", resp.StatusCode)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := http.Get(s.URL + "/service/healthcheck/gtg"); err == nil {
+		t.Errorf("expected server to have shut down")
+	}
+}
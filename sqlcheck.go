@@ -0,0 +1,64 @@
+package goose4
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SQLOptions configures SQLCheck
+type SQLOptions struct {
+	// Query, where set, is run once the database has been pinged successfully, for instance
+	// to check that a schema is in place. The test fails should the query return an error
+	Query string
+	Args  []interface{}
+
+	// Timeout is set as the Timeout of the returned Test
+	Timeout time.Duration
+}
+
+// SQLCheck returns a Test which pings db, and optionally runs a query against it, reporting
+// statistics of the connection pool of db in the test details
+func SQLCheck(name string, db *sql.DB, opts SQLOptions) Test {
+	return Test{
+		Name:    name,
+		Timeout: opts.Timeout,
+		Probe: func(ctx context.Context) (o Outcome) {
+			defer func() {
+				o.Details = dbStats(db.Stats())
+			}()
+
+			if err := db.PingContext(ctx); err != nil {
+				return Outcome{Err: err}
+			}
+
+			if opts.Query == "" {
+				return Outcome{}
+			}
+
+			rows, err := db.QueryContext(ctx, opts.Query, opts.Args...)
+			if err != nil {
+				return Outcome{Err: err}
+			}
+			defer rows.Close()
+
+			// Drain rows, so that errors part way through results are caught
+			for rows.Next() {
+			}
+
+			return Outcome{Err: rows.Err()}
+		},
+	}
+}
+
+// dbStats returns the details reported for a connection pool
+func dbStats(s sql.DBStats) map[string]interface{} {
+	return map[string]interface{}{
+		"max_open_connections": s.MaxOpenConnections,
+		"open_connections":     s.OpenConnections,
+		"in_use":               s.InUse,
+		"idle":                 s.Idle,
+		"wait_count":           s.WaitCount,
+		"wait_duration_millis": int64(s.WaitDuration / time.Millisecond),
+	}
+}
@@ -0,0 +1,109 @@
+package goose4
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeDriver is a database/sql driver whose connections fail to ping where the
+// DSN is "down", and fail queries containing "broken"
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	return fakeConn{dsn}, nil
+}
+
+type fakeConn struct {
+	dsn string
+}
+
+func (c fakeConn) Ping(context.Context) error {
+	if c.dsn == "down" {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query == "broken" {
+		return nil, errors.New("relation does not exist")
+	}
+	return &fakeRows{}, nil
+}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+type fakeRows struct {
+	done bool
+}
+
+func (*fakeRows) Columns() []string { return []string{"one"} }
+func (*fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func init() {
+	sql.Register("goose4fake", fakeDriver{})
+}
+
+func TestSQLCheck(t *testing.T) {
+	for _, test := range []struct {
+		title          string
+		dsn            string
+		opts           SQLOptions
+		expectedResult string
+		expectedMsg    string
+	}{
+		{"A healthy database", "up", SQLOptions{}, ResultPassed, ""},
+		{"An unreachable database", "down", SQLOptions{}, ResultFailed, "connection refused"},
+		{"A successful query", "up", SQLOptions{Query: "SELECT 1"}, ResultPassed, ""},
+		{"A failing query", "up", SQLOptions{Query: "broken"}, ResultFailed, "relation does not exist"},
+	} {
+		t.Run(test.title, func(t *testing.T) {
+			db, err := sql.Open("goose4fake", test.dsn)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			defer db.Close()
+
+			db.SetMaxOpenConns(4)
+
+			t0 := SQLCheck("database", db, test.opts)
+			t0.run(context.Background(), 0)
+
+			t.Run("Result", func(t *testing.T) {
+				if t0.Result != test.expectedResult {
+					t.Errorf("expected %q, received %q", test.expectedResult, t0.Result)
+				}
+
+				if t0.Message != test.expectedMsg {
+					t.Errorf("expected %q, received %q", test.expectedMsg, t0.Message)
+				}
+			})
+
+			t.Run("Pool statistics", func(t *testing.T) {
+				for _, k := range []string{"open_connections", "in_use", "idle", "wait_count", "wait_duration_millis"} {
+					if _, ok := t0.Details[k]; !ok {
+						t.Errorf("expected details to contain %q, received %v", k, t0.Details)
+					}
+				}
+
+				if t0.Details["max_open_connections"] != 4 {
+					t.Errorf("expected 4, received %v", t0.Details["max_open_connections"])
+				}
+			})
+		})
+	}
+}
@@ -15,29 +15,42 @@ import (
 type Status struct {
 	Config
 	System
+
+	// Maintenance is the maintenance in effect, if any
+	Maintenance *Maintenance
+
+	// Shutdown is the shutdown in progress, if any
+	Shutdown *Shutdown
 }
 
 // Marshal returns a status doc based on passed in config and up-to-date
 // system details
 func (s Status) Marshal(boot time.Time) ([]byte, error) {
 	status := Status{
-		Config: s.Config,
-		System: NewSystem(boot),
+		Config:      s.Config,
+		System:      NewSystem(boot),
+		Maintenance: s.Maintenance,
+		Shutdown:    s.Shutdown,
 	}
+	status.System.Legacy = s.System.Legacy
 
 	return json.Marshal(status)
 }
 
 // System contains system specific data for status responses
 type System struct {
-	MachineName string `json:"machine_name"`
-	OSArch      string `json:"os_arch"`
-	OSLoad      string `json:"os_avgload"`
-	OSName      string `json:"os_name"`
-	OSProcs     string `json:"os_numprocessors"`
-	OSVersion   string `json:"os_version"`
-	UpDuration  string `json:"up_duration"`
-	UpSince     string `json:"up_since"`
+	MachineName string        `json:"machine_name"`
+	OSArch      string        `json:"os_arch"`
+	OSLoad      string        `json:"os_avgload"`
+	OSName      string        `json:"os_name"`
+	OSProcs     string        `json:"os_numprocessors"`
+	OSVersion   string        `json:"os_version"`
+	UpDuration  time.Duration `json:"up_duration"`
+	UpSince     time.Time     `json:"up_since"`
+
+	// Legacy serves UpDuration as a go duration string, rather than in milliseconds,
+	// and UpSince in go's default time format, rather than ISO-8601
+	Legacy bool `json:"-"`
 }
 
 // NewSystem will generate a goose4.System and fill it with information
@@ -48,13 +61,13 @@ func NewSystem(boot time.Time) System {
 	c, _ := cpu.Counts(true)
 
 	return System{
-		h.Hostname,
-		runtime.GOARCH,
-		strconv.Itoa(int(l.Load1)),
-		h.OS,
-		strconv.Itoa(c),
-		h.PlatformVersion,
-		time.Since(boot).String(),
-		boot.String(),
+		MachineName: h.Hostname,
+		OSArch:      runtime.GOARCH,
+		OSLoad:      strconv.Itoa(int(l.Load1)),
+		OSName:      h.OS,
+		OSProcs:     strconv.Itoa(c),
+		OSVersion:   h.PlatformVersion,
+		UpDuration:  time.Since(boot),
+		UpSince:     boot,
 	}
 }